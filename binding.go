@@ -18,7 +18,7 @@ type Binding struct {
 }
 
 func (self *Binding) Dump() {
-	fmt.Printf("   %s => %s\n", StringValue(self.Sym), String(self.Val))
+	fmt.Fprintf(currentOutput(), "   %s => %s\n", StringValue(self.Sym), String(self.Val))
 }
 
 func BindingWithSymbolAndValue(sym *Data, val *Data) *Binding {
@@ -8,7 +8,10 @@
 package golisp
 
 import (
+	"fmt"
 	. "gopkg.in/check.v1"
+	"io/ioutil"
+	"path/filepath"
 )
 
 type BuiltinsSuite struct {
@@ -620,3 +623,34 @@ func (s *BuiltinsSuite) TestQuoteShortcut(c *C) {
 
 	c.Assert(NilP(Cddr(result)), Equals, true)
 }
+
+// Load
+
+func (s *BuiltinsSuite) TestLoadReturnsLastValue(c *C) {
+	path := filepath.Join(c.MkDir(), "loaded.lsp")
+	c.Assert(ioutil.WriteFile(path, []byte("(define load-test-x 20)\n; a comment\n(define (load-test-f) (+ load-test-x 1))\n(load-test-f)\n"), 0644), IsNil)
+	result, err := ParseAndEval(fmt.Sprintf("(load %q)", path))
+	c.Assert(err, IsNil)
+	c.Assert(IntegerValue(result), Equals, int64(21))
+	c.Assert(IntegerValue(Global.ValueOf(Intern("load-test-x"))), Equals, int64(20))
+}
+
+func (s *BuiltinsSuite) TestLoadMissingFile(c *C) {
+	path := filepath.Join(c.MkDir(), "missing.lsp")
+	_, err := ParseAndEval(fmt.Sprintf("(load %q)", path))
+	c.Assert(err, ErrorMatches, "(?s).*load could not read .*missing.lsp.*")
+}
+
+func (s *BuiltinsSuite) TestLoadNamesFailingForm(c *C) {
+	path := filepath.Join(c.MkDir(), "failing.lsp")
+	c.Assert(ioutil.WriteFile(path, []byte("(define load-test-y 1)\n(car 1 2 3)\n"), 0644), IsNil)
+	_, err := ParseAndEval(fmt.Sprintf("(load %q)", path))
+	c.Assert(err, ErrorMatches, "(?s).*load failed in .*failing.lsp evaluating \\(car 1 2 3\\).*")
+}
+
+func (s *BuiltinsSuite) TestLoadNamesUnparsableForm(c *C) {
+	path := filepath.Join(c.MkDir(), "unparsable.lsp")
+	c.Assert(ioutil.WriteFile(path, []byte("(define load-test-z 1)\n(list 1 #\\ 2)\n"), 0644), IsNil)
+	_, err := ParseAndEval(fmt.Sprintf("(load %q)", path))
+	c.Assert(err, ErrorMatches, "(?s).*load could not parse the form after \\(define load-test-z 1\\) in .*unparsable.lsp.*")
+}
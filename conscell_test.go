@@ -9,6 +9,8 @@ package golisp
 
 import (
 	. "gopkg.in/check.v1"
+	"sync/atomic"
+	"time"
 )
 
 type ConsCellSuite struct {
@@ -40,3 +42,39 @@ func (s *ConsCellSuite) TestCdr(c *C) {
 func (s *ConsCellSuite) TestCdrNil(c *C) {
 	c.Check(Cdr(nil), IsNil)
 }
+
+func (s *ConsCellSuite) TestConsAllocationsAreCountedPerGoroutine(c *C) {
+	var count int64
+	var stopConsing int32
+	consing := make(chan bool)
+	go func() {
+		for atomic.LoadInt32(&stopConsing) == 0 {
+			Cons(s.a, s.b)
+		}
+		consing <- true
+	}()
+
+	stop := startCountingConsAllocations(&count)
+	Cons(s.a, s.b)
+	Cons(s.a, s.b)
+	time.Sleep(10 * time.Millisecond)
+	stop()
+	atomic.StoreInt32(&stopConsing, 1)
+	<-consing
+
+	c.Assert(atomic.LoadInt64(&count), Equals, int64(2))
+	Cons(s.a, s.b)
+	c.Assert(atomic.LoadInt64(&count), Equals, int64(2))
+}
+
+func (s *ConsCellSuite) TestNestedConsAllocationCounts(c *C) {
+	var outer, inner int64
+	stopOuter := startCountingConsAllocations(&outer)
+	Cons(s.a, s.b)
+	stopInner := startCountingConsAllocations(&inner)
+	Cons(s.a, s.b)
+	stopInner()
+	stopOuter()
+	c.Assert(atomic.LoadInt64(&inner), Equals, int64(1))
+	c.Assert(atomic.LoadInt64(&outer), Equals, int64(2))
+}
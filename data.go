@@ -8,12 +8,17 @@
 package golisp
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"os"
+	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"unsafe"
 
@@ -37,6 +42,31 @@ const (
 	FrameType
 	EnvironmentType
 	PortType
+	BigIntegerType
+)
+
+// Type masks, one bit per type above, used to declare the types a primitive
+// accepts for each argument and returns.
+
+const (
+	NilTypeMask = 1 << iota
+	ConsCellTypeMask
+	AlistTypeMask
+	AlistCellTypeMask
+	IntegerTypeMask
+	FloatTypeMask
+	BooleanTypeMask
+	StringTypeMask
+	SymbolTypeMask
+	FunctionTypeMask
+	MacroTypeMask
+	PrimitiveTypeMask
+	BoxedObjectTypeMask
+	FrameTypeMask
+	EnvironmentTypeMask
+	PortTypeMask
+	BigIntegerTypeMask
+	AnyTypeMask = 0xFFFFFFFF
 )
 
 type ConsCell struct {
@@ -50,8 +80,10 @@ type BoxedObject struct {
 }
 
 type Data struct {
-	Type  uint8
-	Value unsafe.Pointer
+	Type uint8
+	// Generated marks a symbol made by gensym.
+	Generated bool
+	Value     unsafe.Pointer
 }
 
 // Boolean constants
@@ -120,11 +152,30 @@ func TypeName(t uint8) string {
 		return "Environment"
 	case PortType:
 		return "Port"
+	case BigIntegerType:
+		return "Big Integer"
 	default:
 		return "Unknown"
 	}
 }
 
+func TypeMaskOf(d *Data) uint32 {
+	return 1 << TypeOf(d)
+}
+
+func TypeMaskName(mask uint32) string {
+	if mask == AnyTypeMask {
+		return "Anything"
+	}
+	names := make([]string, 0, 1)
+	for t := uint8(NilType); t <= BigIntegerType; t++ {
+		if mask&(1<<t) != 0 {
+			names = append(names, TypeName(t))
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
 // Function has heavy traffic, try to keep it fast
 func NilP(d *Data) bool {
 	if d == nil {
@@ -186,8 +237,19 @@ func FloatP(d *Data) bool {
 	return d != nil && TypeOf(d) == FloatType
 }
 
+// Big integers are integers of any size, made by big or by integer arithmetic
+// that overflows int64.
+func BigIntegerP(d *Data) bool {
+	return d != nil && TypeOf(d) == BigIntegerType
+}
+
+// ExactIntegerP is true of both integers and big integers.
+func ExactIntegerP(d *Data) bool {
+	return IntegerP(d) || BigIntegerP(d)
+}
+
 func NumberP(d *Data) bool {
-	return IntegerP(d) || FloatP(d)
+	return IntegerP(d) || FloatP(d) || BigIntegerP(d)
 }
 
 func ObjectP(d *Data) bool {
@@ -222,12 +284,72 @@ func PortP(d *Data) bool {
 	return d != nil && TypeOf(d) == PortType
 }
 
+// While allocations-during is running a thunk, the goroutine evaluating it
+// has a counter here, keyed by goroutine id, that the cons constructors add
+// the cells they allocate to. Cells allocated by other goroutines aren't
+// counted. consCounting is the number of counters, so that the rest of the
+// time the constructors only check it.
+var consCounting int32
+var consCounters = make(map[uint64]*int64)
+var consCountersMutex sync.Mutex
+
+func countConsAllocations(n int64) {
+	if atomic.LoadInt32(&consCounting) == 0 {
+		return
+	}
+	consCountersMutex.Lock()
+	counter := consCounters[goroutineId()]
+	consCountersMutex.Unlock()
+	if counter != nil {
+		atomic.AddInt64(counter, n)
+	}
+}
+
+// Starts counting the cells the calling goroutine allocates in counter,
+// returning a function that stops it. Nested counts add to the outer one when
+// they stop.
+func startCountingConsAllocations(counter *int64) (stop func()) {
+	id := goroutineId()
+	consCountersMutex.Lock()
+	outer := consCounters[id]
+	consCounters[id] = counter
+	consCountersMutex.Unlock()
+	atomic.AddInt32(&consCounting, 1)
+
+	return func() {
+		atomic.AddInt32(&consCounting, -1)
+		consCountersMutex.Lock()
+		if outer != nil {
+			consCounters[id] = outer
+			atomic.AddInt64(outer, atomic.LoadInt64(counter))
+		} else {
+			delete(consCounters, id)
+		}
+		consCountersMutex.Unlock()
+	}
+}
+
+// The id of the calling goroutine, from the first line of its stack trace:
+// "goroutine 123 [running]:".
+func goroutineId() uint64 {
+	var buf [64]byte
+	trace := buf[:runtime.Stack(buf[:], false)]
+	trace = bytes.TrimPrefix(trace, []byte("goroutine "))
+	if i := bytes.IndexByte(trace, ' '); i >= 0 {
+		trace = trace[:i]
+	}
+	id, _ := strconv.ParseUint(string(trace), 10, 64)
+	return id
+}
+
 func EmptyCons() *Data {
+	countConsAllocations(1)
 	cell := ConsCell{Car: nil, Cdr: nil}
 	return &Data{Type: ConsCellType, Value: unsafe.Pointer(&cell)}
 }
 
 func Cons(car *Data, cdr *Data) *Data {
+	countConsAllocations(1)
 	cell := ConsCell{Car: car, Cdr: cdr}
 	return &Data{Type: ConsCellType, Value: unsafe.Pointer(&cell)}
 }
@@ -309,6 +431,7 @@ func RemoveFromListBang(l *Data, item *Data) (result *Data) {
 func Acons(car *Data, cdr *Data, alist *Data) *Data {
 	pair, _ := Assoc(car, alist)
 	if NilP(pair) {
+		countConsAllocations(2)
 		p := ConsCell{Car: car, Cdr: cdr}
 		cell := Data{Type: AlistCellType, Value: unsafe.Pointer(&p)}
 		conscell := ConsCell{Car: &cell, Cdr: alist}
@@ -348,6 +471,10 @@ func IntegerWithValue(n int64) *Data {
 	return &Data{Type: IntegerType, Value: unsafe.Pointer(&n)}
 }
 
+func BigIntegerWithValue(n *big.Int) *Data {
+	return &Data{Type: BigIntegerType, Value: unsafe.Pointer(n)}
+}
+
 func FloatWithValue(n float32) *Data {
 	return &Data{Type: FloatType, Value: unsafe.Pointer(&n)}
 }
@@ -469,9 +596,22 @@ func IntegerValue(d *Data) int64 {
 		return int64(*((*float32)(d.Value)))
 	}
 
+	if BigIntegerP(d) {
+		return (*big.Int)(d.Value).Int64()
+	}
+
 	return 0
 }
 
+// BigIntegerValue returns the value of an integer or big integer as a big.Int,
+// which must not be modified.
+func BigIntegerValue(d *Data) *big.Int {
+	if BigIntegerP(d) {
+		return (*big.Int)(d.Value)
+	}
+	return big.NewInt(IntegerValue(d))
+}
+
 func FloatValue(d *Data) float32 {
 	if d == nil {
 		return 0
@@ -485,6 +625,11 @@ func FloatValue(d *Data) float32 {
 		return float32(*((*int64)(d.Value)))
 	}
 
+	if BigIntegerP(d) {
+		f, _ := new(big.Float).SetInt((*big.Int)(d.Value)).Float32()
+		return f
+	}
+
 	return 0
 }
 
@@ -726,14 +871,12 @@ func QuoteIt(value *Data) (result *Data) {
 	}
 }
 
+// QuoteAll quotes each element of a list. An empty list gives an empty list,
+// so applying a primitive to no arguments passes it none.
 func QuoteAll(d *Data) (result *Data) {
 	var l []*Data = make([]*Data, 0, 10)
-	if d == nil {
-		l = append(l, QuoteIt(nil))
-	} else {
-		for c := d; c != nil; c = Cdr(c) {
-			l = append(l, QuoteIt(Car(c)))
-		}
+	for c := d; NotNilP(c); c = Cdr(c) {
+		l = append(l, QuoteIt(Car(c)))
 	}
 	return ArrayToList(l)
 }
@@ -828,6 +971,14 @@ func IsEqual(d *Data, o *Data) bool {
 		return false
 	}
 
+	// A big integer equals an integer of the same value.
+	if BigIntegerP(d) || BigIntegerP(o) {
+		if ExactIntegerP(d) && ExactIntegerP(o) {
+			return BigIntegerValue(d).Cmp(BigIntegerValue(o)) == 0
+		}
+		return false
+	}
+
 	if AlistP(d) {
 		if !AlistP(o) && !ListP(o) {
 			return false
@@ -915,8 +1066,10 @@ func IsEqual(d *Data, o *Data) bool {
 		return FloatValue(d) == FloatValue(o)
 	case BooleanType:
 		return BooleanValue(d) == BooleanValue(o)
-	case StringType, SymbolType: // check symbols not generated using intern (aka: gensym and gensym-naked)
+	case StringType:
 		return StringValue(d) == StringValue(o)
+	case SymbolType: // symbols made without Intern compare by name, except for those from gensym
+		return StringValue(d) == StringValue(o) && !GeneratedSymbolP(d) && !GeneratedSymbolP(o)
 	case FunctionType:
 		return FunctionValue(d) == FunctionValue(o)
 	case MacroType:
@@ -930,6 +1083,103 @@ func IsEqual(d *Data, o *Data) bool {
 	return *d == *o
 }
 
+func cellP(d *Data) bool {
+	t := TypeOf(d)
+	return t == ConsCellType || t == AlistType || t == AlistCellType
+}
+
+func frameSlots(f *FrameMap) FrameMapData {
+	f.Mutex.RLock()
+	defer f.Mutex.RUnlock()
+	slots := make(FrameMapData, len(f.Data))
+	for k, v := range f.Data {
+		slots[k] = v
+	}
+	return slots
+}
+
+// IsEqualDeep compares d and o structurally, as equal? does. Unlike IsEqual,
+// numbers are compared by value whatever their type, so 1 is equal to 1.0, and
+// lists and frames that refer back to themselves can be compared.
+func IsEqualDeep(d *Data, o *Data) bool {
+	return isEqualDeep(d, o, make(map[[2]*Data]bool))
+}
+
+// visited holds the pairs of cells and frames already being compared. Meeting
+// a pair again means following a cycle, and any difference along it will be
+// found where the pair was first reached, so it counts as equal.
+func isEqualDeep(d *Data, o *Data, visited map[[2]*Data]bool) bool {
+	if NumberP(d) && NumberP(o) {
+		if IntegerP(d) && IntegerP(o) {
+			return IntegerValue(d) == IntegerValue(o)
+		}
+		if !FloatP(d) && !FloatP(o) {
+			return BigIntegerValue(d).Cmp(BigIntegerValue(o)) == 0
+		}
+		return FloatValue(d) == FloatValue(o)
+	}
+
+	if NilP(d) || NilP(o) {
+		return NilP(d) && NilP(o)
+	}
+
+	// Alists are equal whatever the order of their pairs.
+	if AlistP(d) && AlistP(o) {
+		key := [2]*Data{d, o}
+		if visited[key] {
+			return true
+		}
+		visited[key] = true
+		if Length(d) != Length(o) {
+			return false
+		}
+		for c := d; NotNilP(c); c = Cdr(c) {
+			otherPair, err := Assoc(Caar(c), o)
+			if err != nil || NilP(otherPair) || !isEqualDeep(Cdar(c), Cdr(otherPair), visited) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for cellP(d) && cellP(o) {
+		key := [2]*Data{d, o}
+		if visited[key] {
+			return true
+		}
+		visited[key] = true
+		if !isEqualDeep(Car(d), Car(o), visited) {
+			return false
+		}
+		d, o = Cdr(d), Cdr(o)
+		if NilP(d) || NilP(o) {
+			return NilP(d) && NilP(o)
+		}
+	}
+
+	if FrameP(d) && FrameP(o) {
+		key := [2]*Data{d, o}
+		if visited[key] {
+			return true
+		}
+		visited[key] = true
+		slotsD := frameSlots(FrameValue(d))
+		slotsO := frameSlots(FrameValue(o))
+		if len(slotsD) != len(slotsO) {
+			return false
+		}
+		for k, v := range slotsD {
+			other, found := slotsO[k]
+			if !found || !isEqualDeep(v, other, visited) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return IsEqual(d, o)
+}
+
 func escapeQuotes(str string) string {
 	buffer := make([]rune, 0, 10)
 	for _, ch := range str {
@@ -941,6 +1191,10 @@ func escapeQuotes(str string) string {
 	return string(buffer)
 }
 
+// String returns the printed form of d. The empty list, a Go nil, and the
+// result of an expression with no value (e.g. (if #f #f)) are all the same
+// value in golisp and all print as (). The booleans print as #t and #f; #f is
+// distinct from the empty list even though both are false in a test.
 func String(d *Data) string {
 	if d == nil {
 		return "()"
@@ -1055,6 +1309,8 @@ func String(d *Data) string {
 		return fmt.Sprintf("<environment: %s>", EnvironmentValue(d).Name)
 	case PortType:
 		return fmt.Sprintf("<port: %s>", PortValue(d).Name())
+	case BigIntegerType:
+		return BigIntegerValue(d).String()
 	}
 
 	return ""
@@ -1102,16 +1358,16 @@ func postProcessShortcuts(d *Data) *Data {
 
 func printDashes(indent int) {
 	for i := indent; i > 0; i -= 1 {
-		fmt.Print("-")
+		fmt.Fprint(currentOutput(), "-")
 	}
 }
 
 func logEval(d *Data, env *SymbolTableFrame) {
 	if LispTrace && !DebugEvalInDebugRepl {
 		depth := env.Depth()
-		fmt.Printf("%3d: ", depth)
+		fmt.Fprintf(currentOutput(), "%3d: ", depth)
 		printDashes(depth)
-		fmt.Printf("> %s\n", String(d))
+		fmt.Fprintf(currentOutput(), "> %s\n", String(d))
 		EvalDepth += 1
 	}
 }
@@ -1119,9 +1375,9 @@ func logEval(d *Data, env *SymbolTableFrame) {
 func logResult(result *Data, env *SymbolTableFrame) {
 	if LispTrace && !DebugEvalInDebugRepl {
 		depth := env.Depth()
-		fmt.Printf("%3d: <", depth)
+		fmt.Fprintf(currentOutput(), "%3d: <", depth)
 		printDashes(depth)
-		fmt.Printf(" %s\n", String(result))
+		fmt.Fprintf(currentOutput(), " %s\n", String(result))
 	}
 }
 
@@ -1172,7 +1428,7 @@ func evalHelper(d *Data, env *SymbolTableFrame, needFunction bool) (result *Data
 
 				result, err = Apply(function, args, env)
 				if err != nil {
-					err = errors.New(fmt.Sprintf("\nEvaling %s. %s", String(d), err))
+					err = errorWithMessage(err, fmt.Sprintf("\nEvaling %s. %s", String(d), err))
 					return
 				} else if DebugReturnValue != nil {
 					result = DebugReturnValue
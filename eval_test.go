@@ -26,3 +26,23 @@ func (s *EvalSuite) TestEvalWithNilFunction(c *C) {
 	c.Assert(err, NotNil)
 	c.Assert(result, IsNil)
 }
+
+func (s *EvalSuite) TestUncaughtThrow(c *C) {
+	_, err := ParseAndEval("(throw 'nowhere 1)")
+	c.Assert(err, ErrorMatches, "(?s).*throw to nowhere, but there is no catch for it.")
+	_, isThrown := err.(*ThrownError)
+	c.Assert(isThrown, Equals, false)
+
+	_, err = ParseAndEvalAll("(define x 1) (catch 'a (throw 'b 1)) (set! x 2)")
+	c.Assert(err, ErrorMatches, "(?s).*throw to b, but there is no catch for it.")
+	c.Assert(IntegerValue(Global.ValueOf(Intern("x"))), Equals, int64(1))
+}
+
+func (s *EvalSuite) TestUncaughtThrowFromApply(c *C) {
+	_, err := ParseAndEval("(define (throw-to-tag) (throw 'tag 42))")
+	c.Assert(err, IsNil)
+	_, err = FunctionValue(Global.ValueOf(Intern("throw-to-tag"))).Apply(nil, Global)
+	c.Assert(err, ErrorMatches, "(?s).*throw to tag, but there is no catch for it.")
+	_, isThrown := err.(*ThrownError)
+	c.Assert(isThrown, Equals, false)
+}
@@ -10,12 +10,14 @@ package golisp
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"sync/atomic"
 	"unsafe"
 )
 
 type Function struct {
 	Name             string
+	DocString        string
 	Params           *Data
 	VarArgs          bool
 	RequiredArgCount int
@@ -24,6 +26,17 @@ type Function struct {
 	DebugOnEntry     bool
 	SlotFunction     int32
 	ParentProcess    *Process
+	OptionalParams   []optionalParam
+	KeyParams        []optionalParam
+	RestParam        *Data
+	paramsError      error
+}
+
+// A parameter following &optional or &key, with the expression that provides
+// its value when the caller doesn't. A parameter without a default is nil.
+type optionalParam struct {
+	Name    *Data
+	Default *Data
 }
 
 func computeRequiredArgumentCount(args *Data) (requiredArgumentCount int, varArgs bool) {
@@ -33,6 +46,9 @@ func computeRequiredArgumentCount(args *Data) (requiredArgumentCount int, varArg
 		if SymbolP(a) {
 			varArgs = true
 			return
+		} else if IsEqual(Car(a), Intern("&optional")) || IsEqual(Car(a), Intern("&key")) {
+			varArgs = true
+			return
 		} else {
 			requiredArgumentCount += 1
 		}
@@ -40,29 +56,116 @@ func computeRequiredArgumentCount(args *Data) (requiredArgumentCount int, varArg
 	return
 }
 
+// Parses the &optional and &key sections of a parameter list, e.g.
+// (a &optional (b 10) c &key (d "x") e . rest). A trailing rest parameter can't
+// be combined with &key.
+func parseOptionalParams(params *Data) (optionals []optionalParam, keys []optionalParam, rest *Data, err error) {
+	var section *[]optionalParam
+	a := params
+	for ; PairP(a) && NotNilP(a); a = Cdr(a) {
+		p := Car(a)
+		if IsEqual(p, Intern("&optional")) {
+			if section != nil {
+				return nil, nil, nil, errors.New("&optional must come before &key and only once")
+			}
+			section = &optionals
+			continue
+		}
+		if IsEqual(p, Intern("&key")) {
+			if section == &keys {
+				return nil, nil, nil, errors.New("&key can only appear once")
+			}
+			section = &keys
+			continue
+		}
+		if section == nil {
+			continue
+		}
+		if SymbolP(p) {
+			*section = append(*section, optionalParam{Name: p})
+		} else if PairP(p) && SymbolP(Car(p)) && Length(p) <= 2 {
+			*section = append(*section, optionalParam{Name: Car(p), Default: Cadr(p)})
+		} else {
+			return nil, nil, nil, errors.New(fmt.Sprintf("%s is not a valid optional or keyword parameter; use name or (name default).", String(p)))
+		}
+	}
+	if SymbolP(a) {
+		if len(keys) > 0 {
+			return nil, nil, nil, errors.New("a rest parameter can't be combined with &key")
+		}
+		rest = a
+	}
+	return
+}
+
+// Rewrites a final &rest or &body parameter, as in (test &rest body), as the
+// dotted rest parameter (test . body) that parameter binding expects.
+func normalizeRestParam(params *Data) (result *Data, err error) {
+	var items []*Data
+	for a := params; PairP(a) && NotNilP(a); a = Cdr(a) {
+		p := Car(a)
+		if IsEqual(p, Intern("&rest")) || IsEqual(p, Intern("&body")) {
+			rest := Cdr(a)
+			if !PairP(rest) || NilP(rest) || !SymbolP(Car(rest)) || NotNilP(Cdr(rest)) {
+				return params, errors.New(fmt.Sprintf("%s must be followed by a single parameter name at the end of the parameter list", String(p)))
+			}
+			result = Car(rest)
+			for i := len(items) - 1; i >= 0; i-- {
+				result = Cons(items[i], result)
+			}
+			return
+		}
+		items = append(items, p)
+	}
+	return params, nil
+}
+
 func MakeFunction(name string, params *Data, body *Data, parentEnv *SymbolTableFrame) *Function {
+	params, restErr := normalizeRestParam(params)
 	requiredArgs, varArgs := computeRequiredArgumentCount(params)
-	return &Function{Name: name, Params: params, VarArgs: varArgs, RequiredArgCount: requiredArgs, Body: body, Env: parentEnv, SlotFunction: 0}
+	f := &Function{Name: name, Params: params, VarArgs: varArgs, RequiredArgCount: requiredArgs, Body: body, Env: parentEnv, SlotFunction: 0}
+	f.OptionalParams, f.KeyParams, f.RestParam, f.paramsError = parseOptionalParams(params)
+	if restErr != nil {
+		f.paramsError = restErr
+	}
+	// A string starting a body with more forms after it documents the function.
+	if StringP(Car(body)) && NotNilP(Cdr(body)) {
+		f.DocString = StringValue(Car(body))
+	}
+	return f
+}
+
+func (self *Function) hasOptionalParams() bool {
+	return len(self.OptionalParams) > 0 || len(self.KeyParams) > 0
 }
 
 func (self *Function) String() string {
 	return fmt.Sprintf("<func: %s>", self.Name)
 }
 
-func (self *Function) makeLocalBindings(args *Data, argEnv *SymbolTableFrame, localEnv *SymbolTableFrame, eval bool) (err error) {
+// Binds the (optionally evaluated) arguments in localEnv, returning their values.
+func (self *Function) makeLocalBindings(args *Data, argEnv *SymbolTableFrame, localEnv *SymbolTableFrame, eval bool) (argValues []*Data, err error) {
+	if self.paramsError != nil {
+		return nil, errors.New(fmt.Sprintf("%s has an invalid parameter list: %s", self.Name, self.paramsError))
+	}
+	if self.hasOptionalParams() {
+		return self.makeOptionalBindings(args, argEnv, localEnv, eval)
+	}
+
 	if self.VarArgs {
 		if Length(args) < self.RequiredArgCount {
-			return errors.New(fmt.Sprintf("%s expected at least %d parameters, received %d.", self.Name, self.RequiredArgCount, Length(args)))
+			return nil, errors.New(fmt.Sprintf("%s expected at least %d parameters, received %d.", self.Name, self.RequiredArgCount, Length(args)))
 		}
 	} else {
 		if Length(args) != self.RequiredArgCount {
-			return errors.New(fmt.Sprintf("%s expected %d parameters, received %d.", self.Name, self.RequiredArgCount, Length(args)))
+			return nil, errors.New(fmt.Sprintf("%s expected %d parameters, received %d.", self.Name, self.RequiredArgCount, Length(args)))
 		}
 	}
 
 	var argValue *Data
 	var accumulatingParam *Data = nil
 	accumulatedArgs := make([]*Data, 0)
+	argValues = make([]*Data, 0, Length(args))
 	for p, a := self.Params, args; NotNilP(a); a = Cdr(a) {
 		if eval {
 			argValue, err = Eval(Car(a), argEnv)
@@ -72,6 +175,7 @@ func (self *Function) makeLocalBindings(args *Data, argEnv *SymbolTableFrame, lo
 		} else {
 			argValue = Car(a)
 		}
+		argValues = append(argValues, argValue)
 
 		if accumulatingParam != nil {
 			accumulatedArgs = append(accumulatedArgs, argValue)
@@ -94,10 +198,125 @@ func (self *Function) makeLocalBindings(args *Data, argEnv *SymbolTableFrame, lo
 			return
 		}
 	}
-	return nil
+	return argValues, nil
+}
+
+// Binds the required, &optional, and &key parameters (and any rest parameter)
+// in localEnv. Missing optional and keyword arguments get their defaults,
+// which are evaluated in localEnv so they can refer to earlier parameters.
+// Keyword arguments are passed as name: value pairs, in any order.
+func (self *Function) makeOptionalBindings(args *Data, argEnv *SymbolTableFrame, localEnv *SymbolTableFrame, eval bool) (argValues []*Data, err error) {
+	if Length(args) < self.RequiredArgCount {
+		return nil, errors.New(fmt.Sprintf("%s expected at least %d parameters, received %d.", self.Name, self.RequiredArgCount, Length(args)))
+	}
+
+	var argValue *Data
+	argValues = make([]*Data, 0, Length(args))
+	for a := args; NotNilP(a); a = Cdr(a) {
+		if eval {
+			argValue, err = Eval(Car(a), argEnv)
+			if err != nil {
+				return
+			}
+		} else {
+			argValue = Car(a)
+		}
+		argValues = append(argValues, argValue)
+	}
+
+	p := self.Params
+	for _, v := range argValues[:self.RequiredArgCount] {
+		_, err = localEnv.BindLocallyTo(Car(p), v)
+		if err != nil {
+			return
+		}
+		p = Cdr(p)
+	}
+
+	remaining := argValues[self.RequiredArgCount:]
+	for _, param := range self.OptionalParams {
+		if len(remaining) > 0 {
+			argValue, remaining = remaining[0], remaining[1:]
+		} else {
+			argValue, err = Eval(param.Default, localEnv)
+			if err != nil {
+				return
+			}
+		}
+		_, err = localEnv.BindLocallyTo(param.Name, argValue)
+		if err != nil {
+			return
+		}
+	}
+
+	if self.RestParam != nil {
+		_, err = localEnv.BindLocallyTo(self.RestParam, ArrayToList(remaining))
+		return
+	}
+
+	if len(self.KeyParams) == 0 {
+		if len(remaining) > 0 {
+			return nil, errors.New(fmt.Sprintf("%s expected at most %d parameters, received %d.", self.Name, self.RequiredArgCount+len(self.OptionalParams), len(argValues)))
+		}
+		return
+	}
+
+	if len(remaining)%2 != 0 {
+		return nil, errors.New(fmt.Sprintf("%s expected keyword arguments as name: value pairs, but received %s.", self.Name, String(ArrayToList(remaining))))
+	}
+	supplied := make(map[string]*Data, len(remaining)/2)
+	for i := 0; i < len(remaining); i += 2 {
+		key := remaining[i]
+		if !NakedP(key) {
+			return nil, errors.New(fmt.Sprintf("%s expected a keyword like name:, but received %s.", self.Name, String(key)))
+		}
+		supplied[strings.TrimSuffix(StringValue(key), ":")] = remaining[i+1]
+	}
+	for _, param := range self.KeyParams {
+		name := StringValue(param.Name)
+		value, found := supplied[name]
+		if found {
+			delete(supplied, name)
+		} else {
+			value, err = Eval(param.Default, localEnv)
+			if err != nil {
+				return
+			}
+		}
+		_, err = localEnv.BindLocallyTo(param.Name, value)
+		if err != nil {
+			return
+		}
+	}
+	for name := range supplied {
+		return nil, errors.New(fmt.Sprintf("%s has no keyword parameter named %s.", self.Name, name))
+	}
+	return
 }
 
+// A tailCall is a call to a function in tail position that has been
+// evaluated up to the point of applying it.
+type tailCall struct {
+	Function *Function
+	Args     *Data
+}
+
+// Applies the function, running calls it makes to functions in tail position
+// in this loop rather than recursively, so tail recursion doesn't grow the
+// Go stack.
 func (self *Function) internalApply(args *Data, argEnv *SymbolTableFrame, frame *FrameMap, eval bool) (result *Data, err error) {
+	f := self
+	var next *tailCall
+	for {
+		result, next, err = f.applyOnce(args, argEnv, frame, eval)
+		if err != nil || next == nil {
+			return
+		}
+		f, args, frame, eval = next.Function, next.Args, nil, false
+	}
+}
+
+func (self *Function) applyOnce(args *Data, argEnv *SymbolTableFrame, frame *FrameMap, eval bool) (result *Data, next *tailCall, err error) {
 	localEnv := NewSymbolTableFrameBelowWithFrame(self.Env, frame, self.Name)
 	localEnv.Previous = argEnv
 	selfSym := Intern("self")
@@ -125,7 +344,13 @@ func (self *Function) internalApply(args *Data, argEnv *SymbolTableFrame, frame
 		}
 	}
 
-	err = self.makeLocalBindings(args, argEnv, localEnv, eval)
+	argValues, err := self.makeLocalBindings(args, argEnv, localEnv, eval)
+	if err != nil {
+		return
+	}
+
+	before, after := adviceFor(self.Name)
+	err = runAdvice(before, argValues, argEnv)
 	if err != nil {
 		return
 	}
@@ -135,15 +360,139 @@ func (self *Function) internalApply(args *Data, argEnv *SymbolTableFrame, frame
 	ProfileEnter("func", self.Name, localGuid)
 
 	for s := self.Body; NotNilP(s); s = Cdr(s) {
-		result, err = Eval(Car(s), localEnv)
+		// After advice needs the result, so the last call can't be left to the caller.
+		if NilP(Cdr(s)) && len(after) == 0 {
+			result, next, err = evalTail(Car(s), localEnv)
+		} else {
+			result, err = Eval(Car(s), localEnv)
+		}
 		if err != nil {
-			result, err = nil, errors.New(fmt.Sprintf("In '%s': %s", self.Name, err))
+			result, err = nil, errorWithMessage(err, fmt.Sprintf("In '%s': %s", self.Name, err))
 			break
 		}
 	}
 
 	ProfileExit("func", self.Name, localGuid)
 
+	if err == nil {
+		err = runAdvice(after, []*Data{result}, argEnv)
+	}
+
+	return
+}
+
+// Evaluates form, in tail position if tail is set.
+func evalMaybeTail(form *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
+	if tail {
+		return evalTail(form, env)
+	}
+	result, err = Eval(form, env)
+	return
+}
+
+// Evaluates a body, the last form in tail position if tail is set.
+func evalBodyMaybeTail(body *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
+	for s := body; NotNilP(s); s = Cdr(s) {
+		if NilP(Cdr(s)) {
+			return evalMaybeTail(Car(s), env, tail)
+		}
+		result, err = Eval(Car(s), env)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Functions that are slots of a frame, or that debug-on-entry is watching,
+// are applied as usual rather than in the loop of internalApply.
+func tailCallableP(fn *Function) bool {
+	return atomic.LoadInt32(&fn.SlotFunction) != 1 && !DebugOnEntry.Has(fn.Name)
+}
+
+// Calls f with args, which have already been evaluated. In tail position a
+// call to a function is returned as a tailCall instead.
+func applyMaybeTail(f *Data, args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
+	if tail && FunctionP(f) && tailCallableP(FunctionValue(f)) {
+		return nil, &tailCall{Function: FunctionValue(f), Args: args}, nil
+	}
+	result, err = ApplyWithoutEval(f, args, env)
+	return
+}
+
+// A tailForm is a special form that passes tail position on to some of its
+// subforms. Eval evaluates the form's arguments, with those subforms in tail
+// position if tail is set; Positions returns those subforms, for tail-call?.
+type tailForm struct {
+	Eval      func(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error)
+	Positions func(args *Data) []*Data
+}
+
+// The special forms that evalTail passes tail position through, by name. The
+// special forms themselves are implemented by the same Eval functions with
+// tail unset.
+var tailForms map[string]tailForm
+
+func init() {
+	tailForms = map[string]tailForm{
+		"if":     {evalIf, ifTailPositions},
+		"when":   {evalWhen, whenTailPositions},
+		"unless": {evalUnless, whenTailPositions},
+		"begin":  {evalBegin, bodyTailPositions},
+		"and":    {evalAnd, bodyTailPositions},
+		"or":     {evalOr, bodyTailPositions},
+		"cond":   {evalCond, condTailPositions},
+		"case":   {evalCase, caseTailPositions},
+		"let":    {evalLet, letTailPositions},
+		"let*":   {evalLetStar, letTailPositions},
+		"letrec": {evalLetRec, letTailPositions},
+		"do":     {evalDo, doTailPositions},
+	}
+}
+
+// Evaluates a form in tail position. A call to a function is returned as a
+// tailCall, with its arguments evaluated, instead of being applied, and the
+// special forms in tailForms pass tail position on. Anything else, or
+// anything while debugging or tracing, is simply evaluated.
+func evalTail(form *Data, env *SymbolTableFrame) (result *Data, next *tailCall, err error) {
+	if !PairP(form) || NilP(form) || !SymbolP(Car(form)) || LispTrace || DebugSingleStep || DebugCurrentFrame != nil {
+		result, err = Eval(form, env)
+		return
+	}
+	form = postProcessShortcuts(form)
+	if !PairP(form) || NilP(form) || !SymbolP(Car(form)) {
+		result, err = Eval(form, env)
+		return
+	}
+
+	f := env.ValueOfWithFunctionSlotCheck(Car(form), true)
+	args := Cdr(form)
+
+	if FunctionP(f) {
+		fn := FunctionValue(f)
+		if !tailCallableP(fn) {
+			result, err = Eval(form, env)
+			return
+		}
+		values := make([]*Data, 0, Length(args))
+		var value *Data
+		for a := args; NotNilP(a); a = Cdr(a) {
+			value, err = Eval(Car(a), env)
+			if err != nil {
+				return
+			}
+			values = append(values, value)
+		}
+		return nil, &tailCall{Function: fn, Args: ArrayToList(values)}, nil
+	}
+
+	if PrimitiveP(f) && PrimitiveValue(f).Special && PrimitiveValue(f).checkArgumentCount(Length(args)) {
+		if tf, ok := tailForms[PrimitiveValue(f).Name]; ok {
+			return tf.Eval(args, env, true)
+		}
+	}
+
+	result, err = Eval(form, env)
 	return
 }
 
@@ -165,7 +514,8 @@ func (self *Function) ApplyWithoutEvalWithFrame(args *Data, argEnv *SymbolTableF
 
 func (self *Function) ApplyOveriddingEnvironment(args *Data, argEnv *SymbolTableFrame) (result *Data, err error) {
 	localEnv := NewSymbolTableFrameBelow(argEnv, self.Name)
-	err = self.makeLocalBindings(args, argEnv, localEnv, true)
+	localEnv.Previous = argEnv
+	_, err = self.makeLocalBindings(args, argEnv, localEnv, true)
 	if err != nil {
 		return
 	}
@@ -177,7 +527,7 @@ func (self *Function) ApplyOveriddingEnvironment(args *Data, argEnv *SymbolTable
 	for s := self.Body; NotNilP(s); s = Cdr(s) {
 		result, err = Eval(Car(s), localEnv)
 		if err != nil {
-			result, err = nil, errors.New(fmt.Sprintf("In '%s': %s", self.Name, err))
+			result, err = nil, errorWithMessage(err, fmt.Sprintf("In '%s': %s", self.Name, err))
 			break
 		}
 	}
@@ -0,0 +1,156 @@
+// Copyright 2014 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file implements Go<->Lisp value conversions and calling Lisp functions from Go.
+
+package golisp
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// FromGo converts a Go value to Lisp data:
+//   nil                         -> ()
+//   *Data                       -> itself
+//   bool                        -> boolean
+//   any signed or unsigned int  -> integer
+//   float32, float64            -> float
+//   string                      -> string
+//   []byte                      -> bytearray (sharing the slice's contents)
+//   slice or array              -> list of the converted elements
+//   map with string keys        -> alist with string keys, as JsonToLisp makes;
+//                                  the order of the entries is unspecified
+// Anything else is boxed as a Go object named by its type, holding a pointer
+// to an interface{} containing the value.
+func FromGo(v interface{}) *Data {
+	if v == nil {
+		return nil
+	}
+
+	switch value := v.(type) {
+	case *Data:
+		return value
+	case bool:
+		return BooleanWithValue(value)
+	case string:
+		return StringWithValue(value)
+	case []byte:
+		return ObjectWithTypeAndValue("[]byte", unsafe.Pointer(&value))
+	}
+
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return IntegerWithValue(r.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return IntegerWithValue(int64(r.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return FloatWithValue(float32(r.Float()))
+	case reflect.Slice, reflect.Array:
+		items := make([]*Data, 0, r.Len())
+		for i := 0; i < r.Len(); i++ {
+			items = append(items, FromGo(r.Index(i).Interface()))
+		}
+		return ArrayToList(items)
+	case reflect.Map:
+		if r.Type().Key().Kind() == reflect.String {
+			var alist *Data
+			for _, key := range r.MapKeys() {
+				alist = Acons(StringWithValue(key.String()), FromGo(r.MapIndex(key).Interface()), alist)
+			}
+			return alist
+		}
+	}
+
+	return ObjectWithTypeAndValue(r.Type().String(), unsafe.Pointer(&v))
+}
+
+// ToGo converts Lisp data to a Go value:
+//   ()                -> nil, so empty slices and maps come back as nil
+//   boolean           -> bool
+//   integer           -> int64
+//   float             -> float64
+//   string, symbol    -> string
+//   bytearray         -> []byte
+//   list              -> []interface{} of the converted elements
+//   alist             -> map[string]interface{}, keyed by the names of its
+//                        string or symbol keys
+// Other types, improper lists, and alists with other keys can't be converted
+// and produce an error. Note that a quoted literal like '((a . 1)) is a list
+// of pairs rather than an alist; use alist or acons to build one.
+func ToGo(d *Data) (result interface{}, err error) {
+	if NilP(d) {
+		return nil, nil
+	}
+
+	switch TypeOf(d) {
+	case BooleanType:
+		return BooleanValue(d), nil
+	case IntegerType:
+		return IntegerValue(d), nil
+	case FloatType:
+		return float64(FloatValue(d)), nil
+	case StringType, SymbolType:
+		return StringValue(d), nil
+	case BoxedObjectType:
+		if ObjectType(d) == "[]byte" {
+			return *(*[]byte)(ObjectValue(d)), nil
+		}
+	case AlistType:
+		dict := make(map[string]interface{}, Length(d))
+		for c := d; NotNilP(c); c = Cdr(c) {
+			pair := Car(c)
+			if !StringP(Car(pair)) && !SymbolP(Car(pair)) {
+				return nil, errors.New(fmt.Sprintf("%s can not be converted to a Go map key.", String(Car(pair))))
+			}
+			var value interface{}
+			value, err = ToGo(Cdr(pair))
+			if err != nil {
+				return
+			}
+			dict[StringValue(Car(pair))] = value
+		}
+		return dict, nil
+	case ConsCellType:
+		items := make([]interface{}, 0, Length(d))
+		for c := d; NotNilP(c); c = Cdr(c) {
+			if !ListP(c) {
+				return nil, errors.New(fmt.Sprintf("%s is not a proper list and can not be converted to a Go value.", String(d)))
+			}
+			var item interface{}
+			item, err = ToGo(Car(c))
+			if err != nil {
+				return
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("%s can not be converted to a Go value.", String(d)))
+}
+
+// CallFunction applies the function bound to name in the global environment
+// to args, converted with FromGo, and returns the result converted with ToGo.
+func CallFunction(name string, args ...interface{}) (result interface{}, err error) {
+	f := Global.ValueOf(Intern(name))
+	if !FunctionOrPrimitiveP(f) {
+		return nil, errors.New(fmt.Sprintf("%s is not a function.", name))
+	}
+
+	lispArgs := make([]*Data, 0, len(args))
+	for _, arg := range args {
+		lispArgs = append(lispArgs, FromGo(arg))
+	}
+
+	value, err := ApplyWithoutEval(f, ArrayToList(lispArgs), Global)
+	if err != nil {
+		return
+	}
+	return ToGo(value)
+}
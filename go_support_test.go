@@ -0,0 +1,163 @@
+// Copyright 2014 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file tests Go<->Lisp value conversions and calling Lisp functions from Go.
+
+package golisp
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type GoSupportSuite struct {
+}
+
+var _ = Suite(&GoSupportSuite{})
+
+func (s *GoSupportSuite) SetUpSuite(c *C) {
+	InitLisp()
+	ParseAndEval("(define (go-support-add a b) (+ a b))")
+	ParseAndEval("(define (go-support-greet name) (str \"hello \" name))")
+	ParseAndEval("(define (go-support-double-all l) (map (lambda (x) (* x 2)) l))")
+	ParseAndEval("(define (go-support-positive? x) (> x 0))")
+	ParseAndEval("(define go-support-number 5)")
+}
+
+func (s *GoSupportSuite) TestFromGoScalars(c *C) {
+	c.Assert(FromGo(nil), IsNil)
+	c.Assert(IntegerValue(FromGo(42)), Equals, int64(42))
+	c.Assert(IntegerValue(FromGo(uint8(7))), Equals, int64(7))
+	c.Assert(FloatValue(FromGo(2.5)), Equals, float32(2.5))
+	c.Assert(StringValue(FromGo("hi")), Equals, "hi")
+	c.Assert(BooleanValue(FromGo(true)), Equals, true)
+	c.Assert(String(FromGo([]int{1, 2, 3})), Equals, "(1 2 3)")
+}
+
+func (s *GoSupportSuite) TestToGoScalars(c *C) {
+	v, err := ToGo(IntegerWithValue(3))
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, int64(3))
+
+	v, err = ToGo(FloatWithValue(1.5))
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, float64(1.5))
+
+	v, err = ToGo(Intern("sym"))
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "sym")
+
+	v, err = ToGo(EmptyCons())
+	c.Assert(err, IsNil)
+	c.Assert(v, IsNil)
+
+	_, err = ToGo(Global.ValueOf(Intern("car")))
+	c.Assert(err, NotNil)
+}
+
+func (s *GoSupportSuite) TestFromGoMap(c *C) {
+	d := FromGo(map[string]interface{}{"a": 1})
+	c.Assert(AlistP(d), Equals, true)
+	c.Assert(String(d), Equals, `(("a" . 1))`)
+}
+
+func (s *GoSupportSuite) TestBytesRoundTrip(c *C) {
+	d := FromGo([]byte{1, 2, 3})
+	c.Assert(ObjectType(d), Equals, "[]byte")
+	v, err := ToGo(d)
+	c.Assert(err, IsNil)
+	c.Assert(v, DeepEquals, []byte{1, 2, 3})
+}
+
+func (s *GoSupportSuite) TestNestedRoundTrip(c *C) {
+	original := map[string]interface{}{
+		"name":    "sensor",
+		"enabled": true,
+		"scale":   0.5,
+		"ids":     []interface{}{int64(1), int64(2), int64(3)},
+		"limits": map[string]interface{}{
+			"low":  int64(-10),
+			"high": int64(10),
+		},
+		"readings": []interface{}{
+			map[string]interface{}{"at": int64(0), "values": []interface{}{1.5, 2.5}},
+			map[string]interface{}{"at": int64(1), "values": []interface{}{}},
+		},
+	}
+	v, err := ToGo(FromGo(original))
+	c.Assert(err, IsNil)
+	expected := map[string]interface{}{
+		"name":    "sensor",
+		"enabled": true,
+		"scale":   0.5,
+		"ids":     []interface{}{int64(1), int64(2), int64(3)},
+		"limits": map[string]interface{}{
+			"low":  int64(-10),
+			"high": int64(10),
+		},
+		"readings": []interface{}{
+			map[string]interface{}{"at": int64(0), "values": []interface{}{1.5, 2.5}},
+			map[string]interface{}{"at": int64(1), "values": nil},
+		},
+	}
+	c.Assert(v, DeepEquals, expected)
+}
+
+func (s *GoSupportSuite) TestLispAlistToGo(c *C) {
+	code, _ := Parse("(acons 'b '(1 2) (acons 'a \"x\"))")
+	d, err := Eval(code, Global)
+	c.Assert(err, IsNil)
+	v, err := ToGo(d)
+	c.Assert(err, IsNil)
+	c.Assert(v, DeepEquals, map[string]interface{}{"a": "x", "b": []interface{}{int64(1), int64(2)}})
+}
+
+func (s *GoSupportSuite) TestToGoErrors(c *C) {
+	_, err := ToGo(Cons(IntegerWithValue(1), IntegerWithValue(2)))
+	c.Assert(err, NotNil)
+
+	_, err = ToGo(Acons(IntegerWithValue(1), IntegerWithValue(2), nil))
+	c.Assert(err, NotNil)
+}
+
+func (s *GoSupportSuite) TestCallFunction(c *C) {
+	result, err := CallFunction("go-support-add", 3, 4)
+	c.Assert(err, IsNil)
+	c.Assert(result, Equals, int64(7))
+}
+
+func (s *GoSupportSuite) TestCallFunctionWithStrings(c *C) {
+	result, err := CallFunction("go-support-greet", "world")
+	c.Assert(err, IsNil)
+	c.Assert(result, Equals, "hello world")
+}
+
+func (s *GoSupportSuite) TestCallFunctionWithSlice(c *C) {
+	result, err := CallFunction("go-support-double-all", []int{1, 2, 3})
+	c.Assert(err, IsNil)
+	c.Assert(result, DeepEquals, []interface{}{int64(2), int64(4), int64(6)})
+}
+
+func (s *GoSupportSuite) TestCallFunctionReturningBoolean(c *C) {
+	result, err := CallFunction("go-support-positive?", -1.5)
+	c.Assert(err, IsNil)
+	c.Assert(result, Equals, false)
+}
+
+func (s *GoSupportSuite) TestCallPrimitive(c *C) {
+	result, err := CallFunction("+", 1, 2, 3)
+	c.Assert(err, IsNil)
+	c.Assert(result, Equals, int64(6))
+}
+
+func (s *GoSupportSuite) TestCallFunctionErrors(c *C) {
+	_, err := CallFunction("go-support-no-such-function")
+	c.Assert(err, NotNil)
+
+	_, err = CallFunction("go-support-number")
+	c.Assert(err, NotNil)
+
+	_, err = CallFunction("go-support-add", 1, "two")
+	c.Assert(err, NotNil)
+}
@@ -188,7 +188,7 @@ func JsonStringToLispWithFrames(jsonData string) (result *Data) {
 	var data interface{}
 	err := json.Unmarshal(b, &data)
 	if err != nil {
-		fmt.Printf("Returning empty frame because of badly formed json: '%s'\n --> %v\n", jsonData, err)
+		fmt.Fprintf(currentOutput(), "Returning empty frame because of badly formed json: '%s'\n --> %v\n", jsonData, err)
 		m := FrameMap{}
 		m.Data = make(FrameMapData, 0)
 		return FrameWithValue(&m)
@@ -246,6 +246,10 @@ func LispWithFramesToJson(d *Data) (result interface{}) {
 		return dict
 	}
 
+	if OrderedMapP(d) {
+		return OrderedMapValue(d).toJson(LispWithFramesToJson)
+	}
+
 	return ""
 }
 
@@ -8,15 +8,56 @@
 package golisp
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"unsafe"
 )
 
+// An orderedJsonObject marshals as a JSON object with its keys in the order
+// given, rather than sorted as a map's are.
+type orderedJsonObject struct {
+	Keys   []string
+	Values []interface{}
+}
+
+func (self orderedJsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range self.Keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(self.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// An empty JSON object is an empty alist rather than nil, so it converts back
+// to {} instead of null.
+func emptyJsonObject() *Data {
+	return &Data{Type: AlistType, Value: unsafe.Pointer(&ConsCell{})}
+}
+
 func JsonToLisp(json interface{}) (result *Data) {
 	mapValue, ok := json.(map[string]interface{})
 	if ok {
+		if len(mapValue) == 0 {
+			return emptyJsonObject()
+		}
 		var alist *Data
 		for key, val := range mapValue {
 			value := JsonToLisp(val)
@@ -67,6 +108,100 @@ func JsonStringToLisp(jsonData string) (result *Data) {
 	return JsonToLisp(data)
 }
 
+// ParseJson converts a JSON document to Lisp data: objects become alists with
+// string keys, arrays lists, and null nil. An empty object is an empty alist,
+// which is nil? but converts back to {}. An empty array is the empty list,
+// which is the same as nil, so it converts back to null.
+func ParseJson(jsonData string) (result *Data, err error) {
+	var data interface{}
+	err = json.Unmarshal([]byte(jsonData), &data)
+	if err != nil {
+		return
+	}
+	return JsonToLisp(data), nil
+}
+
+// lispToJsonValue is like LispWithFramesToJson, except nil converts to null
+// and values JSON can't represent are an error rather than "". An empty
+// alist, as ParseJson makes for {}, converts back to {}.
+func lispToJsonValue(d *Data) (result interface{}, err error) {
+	if d != nil && TypeOf(d) == AlistType && NilP(d) {
+		return map[string]interface{}{}, nil
+	}
+	if NilP(d) {
+		return nil, nil
+	}
+
+	switch TypeOf(d) {
+	case IntegerType:
+		return IntegerValue(d), nil
+	case FloatType:
+		return FloatValue(d), nil
+	case BooleanType:
+		return BooleanValue(d), nil
+	case StringType, SymbolType:
+		return StringValue(d), nil
+	case ConsCellType:
+		ary := make([]interface{}, 0, Length(d))
+		for c := d; NotNilP(c); c = Cdr(c) {
+			var value interface{}
+			value, err = lispToJsonValue(Car(c))
+			if err != nil {
+				return
+			}
+			ary = append(ary, value)
+		}
+		return ary, nil
+	case AlistType:
+		dict := make(map[string]interface{}, Length(d))
+		for c := d; NotNilP(c); c = Cdr(c) {
+			pair := Car(c)
+			if !StringP(Car(pair)) && !SymbolP(Car(pair)) {
+				return nil, errors.New(fmt.Sprintf("%s can not be used as a JSON object key.", String(Car(pair))))
+			}
+			var value interface{}
+			value, err = lispToJsonValue(Cdr(pair))
+			if err != nil {
+				return
+			}
+			dict[StringValue(Car(pair))] = value
+		}
+		return dict, nil
+	case FrameType:
+		return LispWithFramesToJson(d), nil
+	}
+
+	if OrderedMapP(d) {
+		obj := OrderedMapValue(d).toJson(func(v *Data) interface{} {
+			value, convertErr := lispToJsonValue(v)
+			if convertErr != nil && err == nil {
+				err = convertErr
+			}
+			return value
+		})
+		if err != nil {
+			return nil, err
+		}
+		return obj, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("%s can not be represented in JSON.", String(d)))
+}
+
+// LispToJsonStringWithError converts Lisp data, as made by ParseJson, to a
+// JSON document.
+func LispToJsonStringWithError(d *Data) (result string, err error) {
+	value, err := lispToJsonValue(d)
+	if err != nil {
+		return
+	}
+	j, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	return string(j), nil
+}
+
 func LispToJson(d *Data) (result interface{}) {
 	if d == nil {
 		return ""
@@ -97,6 +232,10 @@ func LispToJson(d *Data) (result interface{}) {
 		return dict
 	}
 
+	if OrderedMapP(d) {
+		return OrderedMapValue(d).toJson(LispToJson)
+	}
+
 	return ""
 }
 
@@ -96,6 +96,18 @@ func (s *JsonLispSuite) TestLispWithFramesToJsonMixed(c *C) {
 	c.Assert(data, Equals, `{"f3":85,"f4":2.2,"map":{"f1":[47,75],"f2":185}}`)
 }
 
+func (s *JsonLispSuite) TestLispToJsonOrderedMap(c *C) {
+	m := NewOrderedMap()
+	m.Set(StringWithValue("zeta"), IntegerWithValue(1))
+	m.Set(StringWithValue("alpha"), InternalMakeList(IntegerWithValue(2), IntegerWithValue(3)))
+	inner := NewOrderedMap()
+	inner.Set(Intern("y"), StringWithValue("hi"))
+	inner.Set(Intern("x"), IntegerWithValue(4))
+	m.Set(StringWithValue("inner"), OrderedMapWithValue(inner))
+	data := LispToJsonString(OrderedMapWithValue(m))
+	c.Assert(data, Equals, `{"zeta":1,"alpha":[2,3],"inner":{"y":"hi","x":4}}`)
+}
+
 func (s *JsonLispSuite) TestLispToJsonNil(c *C) {
 	data := LispToJsonString(nil)
 	c.Assert(data, Equals, `""`)
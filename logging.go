@@ -22,21 +22,21 @@ func init() {
 }
 
 func LogPrintf(format string, a ...interface{}) {
-	fmt.Printf(format, a...)
+	fmt.Fprintf(currentOutput(), format, a...)
 	for _, logger := range loggers {
 		logger.Printf(format, a...)
 	}
 }
 
 func LogPrint(a ...interface{}) {
-	fmt.Print(a...)
+	fmt.Fprint(currentOutput(), a...)
 	for _, logger := range loggers {
 		logger.Print(a...)
 	}
 }
 
 func LogPrintln(a ...interface{}) {
-	fmt.Println(a...)
+	fmt.Fprintln(currentOutput(), a...)
 	for _, logger := range loggers {
 		logger.Println(a...)
 	}
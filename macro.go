@@ -19,11 +19,15 @@ type Macro struct {
 	RequiredArgCount int
 	Body             *Data
 	Env              *SymbolTableFrame
+	paramsError      error
 }
 
+// The parameters can end with &rest or &body and a name, which is bound to the
+// list of the remaining (unevaluated) arguments, like a dotted rest parameter.
 func MakeMacro(name string, params *Data, body *Data, parentEnv *SymbolTableFrame) *Macro {
+	params, err := normalizeRestParam(params)
 	requiredArgs, varArgs := computeRequiredArgumentCount(params)
-	return &Macro{Name: name, Params: params, VarArgs: varArgs, RequiredArgCount: requiredArgs, Body: body, Env: parentEnv}
+	return &Macro{Name: name, Params: params, VarArgs: varArgs, RequiredArgCount: requiredArgs, Body: body, Env: parentEnv, paramsError: err}
 }
 
 func (self *Macro) String() string {
@@ -31,6 +35,9 @@ func (self *Macro) String() string {
 }
 
 func (self *Macro) makeLocalBindings(args *Data, argEnv *SymbolTableFrame, localEnv *SymbolTableFrame, eval bool) (err error) {
+	if self.paramsError != nil {
+		return errors.New(fmt.Sprintf("%s has an invalid parameter list: %s", self.Name, self.paramsError))
+	}
 	if self.VarArgs {
 		if Length(args) < self.RequiredArgCount {
 			return errors.New(fmt.Sprintf("%s expected at least %d parameters, received %d.", self.Name, self.RequiredArgCount, Length(args)))
@@ -80,6 +87,7 @@ func (self *Macro) makeLocalBindings(args *Data, argEnv *SymbolTableFrame, local
 
 func (self *Macro) Expand(args *Data, argEnv *SymbolTableFrame) (result *Data, err error) {
 	localEnv := NewSymbolTableFrameBelow(self.Env, self.Name)
+	localEnv.Previous = argEnv
 	err = self.makeLocalBindings(args, argEnv, localEnv, false)
 	if err != nil {
 		return
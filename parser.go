@@ -11,40 +11,51 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"os"
+	"strings"
 	"unsafe"
 )
 
 var EofObject *Data = Intern("__EOF__")
 
-func makeInteger(str string) (n *Data, err error) {
+type CaseMode int
+
+const (
+	PreserveCase CaseMode = iota
+	DowncaseSymbols
+	UpcaseSymbols
+)
+
+// ReaderCaseMode controls how the parser folds the case of symbols it reads.
+// Strings are never folded. Builtins are named in lower case, so only
+// PreserveCase and DowncaseSymbols can read code that uses them.
+var ReaderCaseMode CaseMode = PreserveCase
+
+// Integer literals too big for an int64 are read as big integers.
+func makeIntegerInBase(str string, verb string, base int) (n *Data, err error) {
 	var i int64
-	_, err = fmt.Sscanf(str, "%d", &i)
+	_, err = fmt.Sscanf(str, verb, &i)
 	if err != nil {
+		if b, ok := new(big.Int).SetString(str, base); ok {
+			return BigIntegerWithValue(b), nil
+		}
 		return
 	}
 	n = IntegerWithValue(i)
 	return
 }
 
+func makeInteger(str string) (n *Data, err error) {
+	return makeIntegerInBase(str, "%d", 10)
+}
+
 func makeBinaryInteger(str string) (n *Data, err error) {
-	var i int64
-	_, err = fmt.Sscanf(str, "%b", &i)
-	if err != nil {
-		return
-	}
-	n = IntegerWithValue(i)
-	return
+	return makeIntegerInBase(str, "%b", 2)
 }
 
 func makeHexInteger(str string) (n *Data, err error) {
-	var i int64
-	_, err = fmt.Sscanf(str, "%x", &i)
-	if err != nil {
-		return
-	}
-	n = IntegerWithValue(i)
-	return
+	return makeIntegerInBase(str, "%x", 16)
 }
 
 func makeFloat(str string) (n *Data, err error) {
@@ -63,6 +74,12 @@ func makeString(str string) (s *Data, err error) {
 }
 
 func makeSymbol(str string) (s *Data, err error) {
+	switch ReaderCaseMode {
+	case DowncaseSymbols:
+		str = strings.ToLower(str)
+	case UpcaseSymbols:
+		str = strings.ToUpper(str)
+	}
 	s = Intern(str)
 	return
 }
@@ -68,6 +68,20 @@ func (s *ParsingSuite) TestBinaryInteger(c *C) {
 	c.Assert(IntegerValue(sexpr), Equals, int64(10))
 }
 
+func (s *ParsingSuite) TestBigInteger(c *C) {
+	sexpr, err := Parse("123456789012345678901234567890")
+	c.Assert(err, IsNil)
+	c.Assert(int(TypeOf(sexpr)), Equals, BigIntegerType)
+	c.Assert(String(sexpr), Equals, "123456789012345678901234567890")
+}
+
+func (s *ParsingSuite) TestBigHexInteger(c *C) {
+	sexpr, err := Parse("#x10000000000000000")
+	c.Assert(err, IsNil)
+	c.Assert(int(TypeOf(sexpr)), Equals, BigIntegerType)
+	c.Assert(String(sexpr), Equals, "18446744073709551616")
+}
+
 func (s *ParsingSuite) TestFloat(c *C) {
 	sexpr, err := Parse("12.345")
 	c.Assert(err, IsNil)
@@ -100,6 +114,35 @@ func (s *ParsingSuite) TestMixedCaseHexInteger(c *C) {
 	c.Assert(IntegerValue(sexpr), Equals, int64(175))
 }
 
+func (s *ParsingSuite) TestPrefixedIntegers(c *C) {
+	cases := map[string]int64{
+		"0XFF":                               255,
+		"0b1010":                             10,
+		"0B1111":                             15,
+		"-0x10":                              -16,
+		"-0b101":                             -5,
+		"0x0":                                0,
+		"0b0":                                0,
+		"0x7FFFFFFF":                         2147483647,
+		"0x80000000":                         2147483648,
+		"0xFFFFFFFF":                         4294967295,
+		"0b11111111111111111111111111111111": 4294967295,
+		"-0x80000000":                        -2147483648,
+	}
+	for src, expected := range cases {
+		sexpr, err := Parse(src)
+		c.Assert(err, IsNil, Commentf("parsing %s", src))
+		c.Assert(int(TypeOf(sexpr)), Equals, IntegerType, Commentf("parsing %s", src))
+		c.Assert(IntegerValue(sexpr), Equals, expected, Commentf("parsing %s", src))
+	}
+}
+
+func (s *ParsingSuite) TestPrefixedIntegerInList(c *C) {
+	sexpr, err := Parse("(0xff -0b11 0 -7)")
+	c.Assert(err, IsNil)
+	c.Assert(String(sexpr), Equals, "(255 -3 0 -7)")
+}
+
 func (s *ParsingSuite) TestString(c *C) {
 	sexpr, err := Parse(`"test"`)
 	c.Assert(err, IsNil)
@@ -340,6 +383,46 @@ func (s *ParsingSuite) TestParseAndEval(c *C) {
 	c.Assert(IntegerValue(result), Equals, int64(25))
 }
 
+func (s *ParsingSuite) TestReaderPreservesCaseByDefault(c *C) {
+	c.Assert(ReaderCaseMode, Equals, PreserveCase)
+	upper, err := Parse("FOO")
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(upper), Equals, "FOO")
+	lower, err := Parse("foo")
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(lower), Equals, "foo")
+}
+
+func (s *ParsingSuite) TestReaderDowncasesSymbols(c *C) {
+	ReaderCaseMode = DowncaseSymbols
+	defer func() { ReaderCaseMode = PreserveCase }()
+
+	upper, err := Parse("FOO")
+	c.Assert(err, IsNil)
+	c.Assert(upper, Equals, Intern("foo"))
+	lower, err := Parse("foo")
+	c.Assert(err, IsNil)
+	c.Assert(lower, Equals, Intern("foo"))
+	str, err := Parse(`"FOO"`)
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(str), Equals, "FOO")
+}
+
+func (s *ParsingSuite) TestReaderUpcasesSymbols(c *C) {
+	ReaderCaseMode = UpcaseSymbols
+	defer func() { ReaderCaseMode = PreserveCase }()
+
+	upper, err := Parse("FOO")
+	c.Assert(err, IsNil)
+	c.Assert(upper, Equals, Intern("FOO"))
+	lower, err := Parse("'foo")
+	c.Assert(err, IsNil)
+	c.Assert(Cadr(lower), Equals, Intern("FOO"))
+	str, err := Parse(`"foo"`)
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(str), Equals, "foo")
+}
+
 func (s *ParsingSuite) BenchmarkParse(c *C) {
 	c.ResetTimer()
 	for i := 0; i < c.N; i++ {
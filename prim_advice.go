@@ -0,0 +1,152 @@
+// Copyright 2014 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file contains the function advice primitive functions.
+
+package golisp
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Advice is attached to function names. Before advice is applied to the
+// arguments of each call and after advice to its result, in the order they
+// were added. An error from advice aborts the call.
+type functionAdvice struct {
+	Before []*Data
+	After  []*Data
+}
+
+var adviceByName map[string]*functionAdvice = make(map[string]*functionAdvice)
+var adviceMutex sync.RWMutex
+
+func RegisterAdvicePrimitives() {
+	MakePrimitiveFunction("add-advice", "3", AddAdviceImpl)
+	MakePrimitiveFunction("remove-advice", "1|2|3", RemoveAdviceImpl)
+}
+
+func adviceFor(name string) (before []*Data, after []*Data) {
+	adviceMutex.RLock()
+	if advice, found := adviceByName[name]; found {
+		before, after = advice.Before, advice.After
+	}
+	adviceMutex.RUnlock()
+	return
+}
+
+func runAdvice(advice []*Data, args []*Data, env *SymbolTableFrame) (err error) {
+	for _, f := range advice {
+		_, err = ApplyWithoutEval(f, ArrayToList(args), env)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func adviceNameArg(name string, d *Data, env *SymbolTableFrame) (fname string, err error) {
+	if FunctionP(d) {
+		return FunctionValue(d).Name, nil
+	}
+	if !SymbolP(d) && !StringP(d) {
+		err = ProcessError(fmt.Sprintf("%s requires a function name as it's first argument, but received %s.", name, String(d)), env)
+		return
+	}
+	return StringValue(d), nil
+}
+
+func adviceKindArg(name string, d *Data, env *SymbolTableFrame) (before bool, err error) {
+	switch {
+	case IsEqual(d, Intern("before")):
+		return true, nil
+	case IsEqual(d, Intern("after")):
+		return false, nil
+	}
+	err = ProcessError(fmt.Sprintf("%s requires before or after as it's second argument, but received %s.", name, String(d)), env)
+	return
+}
+
+func withoutAdvice(advice []*Data, f *Data) []*Data {
+	remaining := make([]*Data, 0, len(advice))
+	for _, a := range advice {
+		if a != f {
+			remaining = append(remaining, a)
+		}
+	}
+	return remaining
+}
+
+func AddAdviceImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	fname, err := adviceNameArg("add-advice", First(args), env)
+	if err != nil {
+		return
+	}
+	before, err := adviceKindArg("add-advice", Second(args), env)
+	if err != nil {
+		return
+	}
+	f := Third(args)
+	if !FunctionOrPrimitiveP(f) {
+		err = ProcessError(fmt.Sprintf("add-advice requires a function as it's third argument, but received %s.", String(f)), env)
+		return
+	}
+
+	adviceMutex.Lock()
+	defer adviceMutex.Unlock()
+	advice, found := adviceByName[fname]
+	if !found {
+		advice = &functionAdvice{}
+		adviceByName[fname] = advice
+	}
+	// The slices are replaced rather than appended to in place, since calls in
+	// progress may be iterating over the old ones.
+	if before {
+		advice.Before = append(advice.Before[:len(advice.Before):len(advice.Before)], f)
+	} else {
+		advice.After = append(advice.After[:len(advice.After):len(advice.After)], f)
+	}
+	return f, nil
+}
+
+// (remove-advice name [kind [fn]]) removes all the advice on a function, all
+// of one kind, or one particular piece of advice.
+func RemoveAdviceImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	fname, err := adviceNameArg("remove-advice", First(args), env)
+	if err != nil {
+		return
+	}
+
+	adviceMutex.Lock()
+	defer adviceMutex.Unlock()
+	advice, found := adviceByName[fname]
+	if !found {
+		return
+	}
+
+	if Length(args) == 1 {
+		delete(adviceByName, fname)
+		return
+	}
+
+	before, err := adviceKindArg("remove-advice", Second(args), env)
+	if err != nil {
+		return
+	}
+	switch {
+	case Length(args) == 2 && before:
+		advice.Before = nil
+	case Length(args) == 2:
+		advice.After = nil
+	case before:
+		advice.Before = withoutAdvice(advice.Before, Third(args))
+	default:
+		advice.After = withoutAdvice(advice.After, Third(args))
+	}
+	if len(advice.Before) == 0 && len(advice.After) == 0 {
+		delete(adviceByName, fname)
+	}
+	return
+}
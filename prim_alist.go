@@ -7,7 +7,12 @@
 
 package golisp
 
-import ()
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
 
 func RegisterAListPrimitives() {
 	MakePrimitiveFunction("acons", "2|3", AconsImpl)
@@ -18,6 +23,108 @@ func RegisterAListPrimitives() {
 	MakePrimitiveFunction("dissoc", "2", DissocImpl)
 	MakePrimitiveFunction("rassoc", "2", RassocImpl)
 	MakePrimitiveFunction("alist", "1", AlistImpl)
+	MakePrimitiveFunction("parse-options", "2|3", ParseOptionsImpl)
+	MakePrimitiveFunction("alist-remove", "2", AlistRemoveImpl)
+	MakePrimitiveFunction("alist-set", "3", AlistSetImpl)
+	MakePrimitiveFunction("alist-keys", "1", AlistKeysImpl)
+	MakePrimitiveFunction("alist-values", "1", AlistValuesImpl)
+}
+
+func optionName(key *Data) (name string, err error) {
+	if !StringP(key) && !SymbolP(key) {
+		err = errors.New(fmt.Sprintf("Option names must be symbols or strings, but found %s.", String(key)))
+		return
+	}
+	return StringValue(key), nil
+}
+
+// ParseOptions resolves an options alist, keyed by symbols or strings, against
+// a set of defaults keyed by name. The result has every default, replaced by
+// the value given in options if there is one. Options without a default are
+// included too, unless strict is set in which case they are an error.
+func ParseOptions(options *Data, defaults map[string]*Data, strict bool) (resolved map[string]*Data, err error) {
+	if !ListP(options) {
+		err = errors.New(fmt.Sprintf("Options must be an alist, but found %s.", String(options)))
+		return
+	}
+
+	resolved = make(map[string]*Data, len(defaults))
+	for name, value := range defaults {
+		resolved[name] = value
+	}
+
+	var name string
+	for c := options; NotNilP(c); c = Cdr(c) {
+		pair := Car(c)
+		if !DottedPairP(pair) && !PairP(pair) || NilP(pair) {
+			err = errors.New(fmt.Sprintf("Options must be an alist, but found %s.", String(options)))
+			return
+		}
+		name, err = optionName(Car(pair))
+		if err != nil {
+			return
+		}
+		if _, known := defaults[name]; strict && !known {
+			names := make([]string, 0, len(defaults))
+			for n := range defaults {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			err = errors.New(fmt.Sprintf("Unknown option %s, expected one of: %s.", name, strings.Join(names, ", ")))
+			return
+		}
+		resolved[name] = Cdr(pair)
+	}
+	return
+}
+
+// (parse-options options defaults [strict]) returns an alist of the resolved
+// options: the defaults in their order followed by any others that were given.
+func ParseOptionsImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	defaultsAlist := Second(args)
+	if !ListP(defaultsAlist) {
+		err = ProcessError(fmt.Sprintf("parse-options requires an alist of defaults, but received %s.", String(defaultsAlist)), env)
+		return
+	}
+
+	keys := make([]*Data, 0, Length(defaultsAlist))
+	defaults := make(map[string]*Data, Length(defaultsAlist))
+	var name string
+	for c := defaultsAlist; NotNilP(c); c = Cdr(c) {
+		pair := Car(c)
+		if !DottedPairP(pair) && !PairP(pair) || NilP(pair) {
+			err = ProcessError(fmt.Sprintf("parse-options requires an alist of defaults, but received %s.", String(defaultsAlist)), env)
+			return
+		}
+		name, err = optionName(Car(pair))
+		if err != nil {
+			err = ProcessError(err.Error(), env)
+			return
+		}
+		if _, seen := defaults[name]; !seen {
+			keys = append(keys, Car(pair))
+		}
+		defaults[name] = Cdr(pair)
+	}
+
+	options := First(args)
+	resolved, err := ParseOptions(options, defaults, BooleanValue(Third(args)))
+	if err != nil {
+		err = ProcessError(fmt.Sprintf("parse-options: %s", err), env)
+		return
+	}
+
+	for c := options; NotNilP(c); c = Cdr(c) {
+		name, _ = optionName(Car(Car(c)))
+		if _, known := defaults[name]; !known {
+			defaults[name] = nil
+			keys = append(keys, Car(Car(c)))
+		}
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		result = Acons(keys[i], resolved[StringValue(keys[i])], result)
+	}
+	return
 }
 
 func AlistImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -112,3 +219,91 @@ func DissocImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	list := Cadr(args)
 	return Dissoc(key, list)
 }
+
+// Returns the pairs of an alist in order.
+func alistPairs(name string, alist *Data, env *SymbolTableFrame) (pairs []*Data, err error) {
+	if !ListP(alist) {
+		err = ProcessError(fmt.Sprintf("%s requires an alist, but received %s.", name, String(alist)), env)
+		return
+	}
+	for c := alist; NotNilP(c); c = Cdr(c) {
+		pair := Car(c)
+		if !DottedPairP(pair) && !PairP(pair) {
+			err = ProcessError(fmt.Sprintf("%s requires an alist, but received %s.", name, String(alist)), env)
+			return
+		}
+		pairs = append(pairs, pair)
+	}
+	return
+}
+
+// Makes a new alist of the pairs' keys and values, in the same order.
+func alistFromPairs(pairs []*Data) (result *Data) {
+	for i := len(pairs) - 1; i >= 0; i-- {
+		result = Acons(Car(pairs[i]), Cdr(pairs[i]), result)
+	}
+	return
+}
+
+// The alist functions below don't modify their argument. Keys are compared
+// as assoc compares them.
+
+func AlistRemoveImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	key := First(args)
+	pairs, err := alistPairs("alist-remove", Second(args), env)
+	if err != nil {
+		return
+	}
+	for i, pair := range pairs {
+		if IsEqual(Car(pair), key) {
+			return alistFromPairs(append(pairs[:i:i], pairs[i+1:]...)), nil
+		}
+	}
+	return Second(args), nil
+}
+
+// A new key is added at the front, as acons does.
+func AlistSetImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	key := First(args)
+	if PairP(key) {
+		err = ProcessError("Alist key can not be a list", env)
+		return
+	}
+	value := Second(args)
+	pairs, err := alistPairs("alist-set", Third(args), env)
+	if err != nil {
+		return
+	}
+	for i, pair := range pairs {
+		if IsEqual(Car(pair), key) {
+			updated := append([]*Data{}, pairs...)
+			updated[i] = Cons(key, value)
+			return alistFromPairs(updated), nil
+		}
+	}
+	return Acons(key, value, alistFromPairs(pairs)), nil
+}
+
+func AlistKeysImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	pairs, err := alistPairs("alist-keys", Car(args), env)
+	if err != nil {
+		return
+	}
+	keys := make([]*Data, 0, len(pairs))
+	for _, pair := range pairs {
+		keys = append(keys, Car(pair))
+	}
+	return ArrayToList(keys), nil
+}
+
+func AlistValuesImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	pairs, err := alistPairs("alist-values", Car(args), env)
+	if err != nil {
+		return
+	}
+	values := make([]*Data, 0, len(pairs))
+	for _, pair := range pairs {
+		values = append(values, Cdr(pair))
+	}
+	return ArrayToList(values), nil
+}
@@ -17,6 +17,90 @@ func RegisterBinaryPrimitives() {
 	MakePrimitiveFunction("binary-not", "1", BinaryNotImpl)
 	MakePrimitiveFunction("left-shift", "2", LeftShiftImpl)
 	MakePrimitiveFunction("right-shift", "2", RightShiftImpl)
+
+	MakePrimitiveFunction("bit-and", ">=2", BitAndImpl)
+	MakePrimitiveFunction("bit-or", ">=2", BitOrImpl)
+	MakePrimitiveFunction("bit-xor", ">=2", BitXorImpl)
+	MakePrimitiveFunction("bit-not", "1", BitNotImpl)
+	MakePrimitiveFunction("shift-left", "2", ShiftLeftImpl)
+	MakePrimitiveFunction("shift-right", "2", ShiftRightImpl)
+}
+
+// The bit- and shift- primitives work on the full signed 64 bit value of
+// integers, unlike the binary- ones above, which treat them as 32 bit masks.
+
+func bitArgs(name string, args *Data, env *SymbolTableFrame) (values []int64, err error) {
+	values = make([]int64, 0, Length(args))
+	for c := args; NotNilP(c); c = Cdr(c) {
+		arg := Car(c)
+		if !IntegerP(arg) {
+			err = ProcessError(fmt.Sprintf("%s requires integer arguments, but received %s %s.", name, TypeName(TypeOf(arg)), String(arg)), env)
+			return
+		}
+		values = append(values, IntegerValue(arg))
+	}
+	return
+}
+
+func reduceBits(name string, args *Data, env *SymbolTableFrame, op func(int64, int64) int64) (result *Data, err error) {
+	values, err := bitArgs(name, args, env)
+	if err != nil {
+		return
+	}
+	acc := values[0]
+	for _, v := range values[1:] {
+		acc = op(acc, v)
+	}
+	return IntegerWithValue(acc), nil
+}
+
+func BitAndImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return reduceBits("bit-and", args, env, func(a, b int64) int64 { return a & b })
+}
+
+func BitOrImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return reduceBits("bit-or", args, env, func(a, b int64) int64 { return a | b })
+}
+
+func BitXorImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return reduceBits("bit-xor", args, env, func(a, b int64) int64 { return a ^ b })
+}
+
+func BitNotImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	values, err := bitArgs("bit-not", args, env)
+	if err != nil {
+		return
+	}
+	return IntegerWithValue(^values[0]), nil
+}
+
+func shiftArgs(name string, args *Data, env *SymbolTableFrame) (value int64, count uint, err error) {
+	values, err := bitArgs(name, args, env)
+	if err != nil {
+		return
+	}
+	if values[1] < 0 {
+		err = ProcessError(fmt.Sprintf("%s requires a non-negative shift count, but received %d.", name, values[1]), env)
+		return
+	}
+	return values[0], uint(values[1]), nil
+}
+
+func ShiftLeftImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	value, count, err := shiftArgs("shift-left", args, env)
+	if err != nil {
+		return
+	}
+	return IntegerWithValue(value << count), nil
+}
+
+// Shifts arithmetically, so negative numbers stay negative.
+func ShiftRightImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	value, count, err := shiftArgs("shift-right", args, env)
+	if err != nil {
+		return
+	}
+	return IntegerWithValue(value >> count), nil
 }
 
 func BinaryAndImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
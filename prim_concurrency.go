@@ -88,7 +88,7 @@ func ForkImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 			var forkedErr error
 			returnValue, forkedErr = function.ApplyWithoutEval(Cons(procObj, Cdr(args)), env)
 			if forkedErr != nil {
-				fmt.Println(forkedErr)
+				fmt.Fprintln(currentOutput(), forkedErr)
 			}
 		}, "fork")
 	}()
@@ -195,7 +195,7 @@ func ScheduleImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 					var forkedErr error
 					returnValue, forkedErr = function.ApplyWithoutEval(Cons(procObj, Cddr(args)), env)
 					if forkedErr != nil {
-						fmt.Println(forkedErr)
+						fmt.Fprintln(currentOutput(), forkedErr)
 					}
 					break Loop
 				}
@@ -416,7 +416,7 @@ func callWithPanicProtection(f func(), prefix string) {
 			stackBuf = stackBuf[:runtime.Stack(stackBuf, false)]
 			stack := strings.Split(string(stackBuf), "\n")
 			for i := 0; i < 7; i++ {
-				fmt.Println(stack[i])
+				fmt.Fprintln(currentOutput(), stack[i])
 			}
 		}
 	}()
@@ -22,6 +22,7 @@ func RegisterDebugPrimitives() {
 	MakePrimitiveFunction("debug-on-entry", "0", DebugOnEntryImpl)
 	MakePrimitiveFunction("remove-debug-on-entry", "1", RemoveDebugOnEntryImpl)
 	MakePrimitiveFunction("dump", "0", DumpSymbolTableImpl)
+	MakePrimitiveFunction("tail-call?", "2", TailCallPImpl)
 
 	MakeRestrictedPrimitiveFunction("debug", "0", DebugImpl)
 	MakeRestrictedPrimitiveFunction("debug-on-error", "0|1", DebugOnErrorImpl)
@@ -33,6 +34,41 @@ func DumpSymbolTableImpl(args *Data, env *SymbolTableFrame) (result *Data, err e
 	return
 }
 
+func lastForm(forms *Data) *Data {
+	var last *Data
+	for c := forms; NotNilP(c); c = Cdr(c) {
+		last = Car(c)
+	}
+	return last
+}
+
+// Reports whether target appears in a tail position of form: the form itself,
+// or, for the special forms in tailForms, one of the subforms they evaluate in
+// tail position. The bodies of nested lambdas are not tail positions of form.
+func inTailPosition(form *Data, target *Data) bool {
+	if IsEqual(form, target) {
+		return true
+	}
+	if !PairP(form) || NilP(form) || !SymbolP(Car(form)) {
+		return false
+	}
+
+	tf, ok := tailForms[StringValue(Car(form))]
+	if !ok {
+		return false
+	}
+	for _, position := range tf.Positions(Cdr(form)) {
+		if inTailPosition(position, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func TailCallPImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return BooleanWithValue(inTailPosition(First(args), Second(args))), nil
+}
+
 func DebugTraceImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	if Length(args) == 1 {
 		DebugTrace = BooleanValue(Car(args))
@@ -86,7 +122,7 @@ func DebugOnErrorImpl(args *Data, env *SymbolTableFrame) (result *Data, err erro
 }
 
 func DebugImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
-	fmt.Printf("Debugger\n")
+	fmt.Fprintf(currentOutput(), "Debugger\n")
 
 	DebugRepl(env)
 	return
@@ -94,7 +130,7 @@ func DebugImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 
 func processState(tokens []string) (ok bool, state bool) {
 	if len(tokens) != 2 {
-		fmt.Printf("Missing on/off.\n")
+		fmt.Fprintf(currentOutput(), "Missing on/off.\n")
 		return false, false
 	} else {
 		switch tokens[1] {
@@ -103,7 +139,7 @@ func processState(tokens []string) (ok bool, state bool) {
 		case "off":
 			return true, false
 		default:
-			fmt.Printf("on/off expected.\n")
+			fmt.Fprintf(currentOutput(), "on/off expected.\n")
 			return false, false
 		}
 	}
@@ -112,7 +148,7 @@ func processState(tokens []string) (ok bool, state bool) {
 func funcOrNil(fname string, env *SymbolTableFrame) *Data {
 	f := env.ValueOf(Intern(fname))
 	if f == nil || TypeOf(f) != FunctionType {
-		fmt.Printf("No such function\n")
+		fmt.Fprintf(currentOutput(), "No such function\n")
 		return nil
 	}
 	return f
@@ -125,7 +161,7 @@ func DebugRepl(env *SymbolTableFrame) {
 	for true {
 		defer func() {
 			if x := recover(); x != nil {
-				println("Don't Panic!")
+				fmt.Fprintln(currentOutput(), "Don't Panic!")
 			}
 		}()
 		input := *ReadLine(&prompt)
@@ -150,30 +186,30 @@ func DebugRepl(env *SymbolTableFrame) {
 					}
 				case "(":
 					for _, f := range DebugOnEntry.List() {
-						fmt.Printf("%s\n", f)
+						fmt.Fprintf(currentOutput(), "%s\n", f)
 					}
 				case "?":
-					fmt.Printf("SteelSeries/GoLisp Debugger\n")
-					fmt.Printf("---------------------------\n")
-					fmt.Printf(":(+ func  - debug on entry to func\n")
-					fmt.Printf(":(- func  - don't debug on entry to func\n")
-					fmt.Printf(":(        - show functions marked as debug on entry\n")
-					fmt.Printf(":?        - show this command summary\n")
-					fmt.Printf(":b        - show the environment stack\n")
-					fmt.Printf(":c        - continue, exiting the debugger\n")
-					fmt.Printf(":d        - do a full dump of the environment stack\n")
-					fmt.Printf(":e on/off - Enable/disable debug on error\n")
-					fmt.Printf(":f frame# - do a full dump of a single environment frame\n")
-					//fmt.Printf(":n        - step to next (run to the next evaluation in this frame)\n")
-					fmt.Printf(":q        - quit GoLisp\n")
-					fmt.Printf(":r sexpr  - return from the current evaluation with the specified value\n")
-					fmt.Printf(":s        - single step (run to the next evaluation)\n")
-					fmt.Printf(":t on/off - Enable/disable tracing\n")
-					fmt.Printf(":u        - continue until the enclosing environment frame is returned to\n")
-					fmt.Printf("\n")
+					fmt.Fprintf(currentOutput(), "SteelSeries/GoLisp Debugger\n")
+					fmt.Fprintf(currentOutput(), "---------------------------\n")
+					fmt.Fprintf(currentOutput(), ":(+ func  - debug on entry to func\n")
+					fmt.Fprintf(currentOutput(), ":(- func  - don't debug on entry to func\n")
+					fmt.Fprintf(currentOutput(), ":(        - show functions marked as debug on entry\n")
+					fmt.Fprintf(currentOutput(), ":?        - show this command summary\n")
+					fmt.Fprintf(currentOutput(), ":b        - show the environment stack\n")
+					fmt.Fprintf(currentOutput(), ":c        - continue, exiting the debugger\n")
+					fmt.Fprintf(currentOutput(), ":d        - do a full dump of the environment stack\n")
+					fmt.Fprintf(currentOutput(), ":e on/off - Enable/disable debug on error\n")
+					fmt.Fprintf(currentOutput(), ":f frame# - do a full dump of a single environment frame\n")
+					//fmt.Fprintf(currentOutput(), ":n        - step to next (run to the next evaluation in this frame)\n")
+					fmt.Fprintf(currentOutput(), ":q        - quit GoLisp\n")
+					fmt.Fprintf(currentOutput(), ":r sexpr  - return from the current evaluation with the specified value\n")
+					fmt.Fprintf(currentOutput(), ":s        - single step (run to the next evaluation)\n")
+					fmt.Fprintf(currentOutput(), ":t on/off - Enable/disable tracing\n")
+					fmt.Fprintf(currentOutput(), ":u        - continue until the enclosing environment frame is returned to\n")
+					fmt.Fprintf(currentOutput(), "\n")
 				case "b":
 					env.DumpHeaders()
-					fmt.Printf("\n")
+					fmt.Fprintf(currentOutput(), "\n")
 				case "c":
 					DebugCurrentFrame = nil
 					DebugSingleStep = false
@@ -189,11 +225,11 @@ func DebugRepl(env *SymbolTableFrame) {
 				case "f":
 					var fnum int
 					if len(tokens) != 2 {
-						fmt.Printf("Missing frame number.\n")
+						fmt.Fprintf(currentOutput(), "Missing frame number.\n")
 					} else {
 						_, err := fmt.Sscanf(tokens[1], "%d", &fnum)
 						if err != nil {
-							fmt.Printf("Bad frame number: '%s'. %s\n", tokens[1], err)
+							fmt.Fprintf(currentOutput(), "Bad frame number: '%s'. %s\n", tokens[1], err)
 						} else {
 							env.DumpSingleFrame(fnum)
 						}
@@ -208,7 +244,7 @@ func DebugRepl(env *SymbolTableFrame) {
 					d, err := Eval(code, env)
 					DebugEvalInDebugRepl = false
 					if err != nil {
-						fmt.Printf("Error in evaluation: %s\n", err)
+						fmt.Fprintf(currentOutput(), "Error in evaluation: %s\n", err)
 					} else {
 						DebugReturnValue = d
 						DebugCurrentFrame = nil
@@ -229,21 +265,21 @@ func DebugRepl(env *SymbolTableFrame) {
 						DebugCurrentFrame = env
 						return
 					} else {
-						fmt.Printf("Already at top frame.\n")
+						fmt.Fprintf(currentOutput(), "Already at top frame.\n")
 					}
 				}
 			} else {
 				code, err := Parse(input)
 				if err != nil {
-					fmt.Printf("Error: %s\n", err)
+					fmt.Fprintf(currentOutput(), "Error: %s\n", err)
 				} else {
 					DebugEvalInDebugRepl = true
 					d, err := Eval(code, env)
 					DebugEvalInDebugRepl = false
 					if err != nil {
-						fmt.Printf("Error in evaluation: %s\n", err)
+						fmt.Fprintf(currentOutput(), "Error in evaluation: %s\n", err)
 					} else {
-						fmt.Printf("==> %s\n", String(d))
+						fmt.Fprintf(currentOutput(), "==> %s\n", String(d))
 					}
 				}
 			}
@@ -253,7 +289,7 @@ func DebugRepl(env *SymbolTableFrame) {
 
 func ProcessError(errorMessage string, env *SymbolTableFrame) error {
 	if DebugOnError && IsInteractive {
-		fmt.Printf("ERROR!  %s\n", errorMessage)
+		fmt.Fprintf(currentOutput(), "ERROR!  %s\n", errorMessage)
 		DebugRepl(env)
 		return nil
 	} else {
@@ -9,6 +9,8 @@ package golisp
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 )
 
 func RegisterFramePrimitives() {
@@ -25,8 +27,11 @@ func RegisterFramePrimitives() {
 	MakePrimitiveFunction("clone", "1", CloneImpl)
 	MakePrimitiveFunction("json->lisp", "1", JsonToLispImpl)
 	MakePrimitiveFunction("lisp->json", "1", LispToJsonImpl)
+	MakePrimitiveFunction("parse-json", "1", ParseJsonImpl)
+	MakePrimitiveFunction("to-json", "1", ToJsonImpl)
 	MakePrimitiveFunction("frame-keys", "1", FrameKeysImpl)
 	MakePrimitiveFunction("frame-values", "1", FrameValuesImpl)
+	MakePrimitiveFunction("frame->alist", "1", FrameToAlistImpl)
 }
 
 func MakeFrameImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -211,6 +216,7 @@ func SendSuperImpl(args *Data, env *SymbolTableFrame) (result *Data, err error)
 
 	params := Cdr(args)
 	frameEnv := NewSymbolTableFrameBelowWithFrame(env, env.Frame, fmt.Sprintf("%s'", env.Name))
+	frameEnv.Previous = env
 	_, err = frameEnv.BindLocallyTo(Intern("self"), FrameWithValue(env.Frame))
 	if err != nil {
 		return
@@ -319,6 +325,7 @@ func ApplySlotSuperImpl(args *Data, env *SymbolTableFrame) (result *Data, err er
 	}
 
 	frameEnv := NewSymbolTableFrameBelowWithFrame(env, env.Frame, fmt.Sprintf("%s'", env.Name))
+	frameEnv.Previous = env
 	_, err = frameEnv.BindLocallyTo(Intern("self"), FrameWithValue(env.Frame))
 	if err != nil {
 		return
@@ -352,6 +359,29 @@ func LispToJsonImpl(args *Data, env *SymbolTableFrame) (result *Data, err error)
 	return StringWithValue(LispWithFramesToJsonString(l)), nil
 }
 
+func ParseJsonImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	j := Car(args)
+	if !StringP(j) {
+		err = ProcessError(fmt.Sprintf("parse-json requires a string as it's argument, but was given %s.", String(j)), env)
+		return
+	}
+
+	result, err = ParseJson(StringValue(j))
+	if err != nil {
+		err = ProcessError(fmt.Sprintf("parse-json could not parse %s: %s", String(j), err), env)
+	}
+	return
+}
+
+func ToJsonImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	j, err := LispToJsonStringWithError(Car(args))
+	if err != nil {
+		err = ProcessError(fmt.Sprintf("to-json failed: %s", err), env)
+		return
+	}
+	return StringWithValue(j), nil
+}
+
 func FrameKeysImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	f := Car(args)
 	if !FrameP(f) {
@@ -371,3 +401,26 @@ func FrameValuesImpl(args *Data, env *SymbolTableFrame) (result *Data, err error
 
 	return ArrayToList(FrameValue(f).Values()), nil
 }
+
+// Returns an alist of a frame's slots, sorted by name and keyed by strings
+// without the trailing colon, as json->lisp makes for a JSON object.
+func FrameToAlistImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	f := Car(args)
+	if !FrameP(f) {
+		err = ProcessError(fmt.Sprintf("frame->alist requires a frame as it's argument, but was given %s.", String(f)), env)
+		return
+	}
+
+	frame := FrameValue(f)
+	frame.Mutex.RLock()
+	keys := make([]string, 0, len(frame.Data))
+	for k, _ := range frame.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i := len(keys) - 1; i >= 0; i-- {
+		result = Acons(StringWithValue(strings.TrimRight(keys[i], ":")), frame.Data[keys[i]], result)
+	}
+	frame.Mutex.RUnlock()
+	return
+}
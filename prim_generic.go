@@ -0,0 +1,106 @@
+// Copyright 2014 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file contains the generic function primitive functions.
+
+package golisp
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A generic function dispatches on the type-of its first argument to the
+// method registered for that type, falling back to the method registered
+// for the type default.
+type GenericFunction struct {
+	Name    string
+	Methods map[string]*Data
+	Mutex   sync.RWMutex
+}
+
+var genericFunctions = make(map[*PrimitiveFunction]*GenericFunction)
+var genericFunctionsMutex sync.RWMutex
+
+func RegisterGenericPrimitives() {
+	MakeSpecialForm("define-generic", "1", DefineGenericImpl)
+	MakeSpecialForm("define-method", "3", DefineMethodImpl)
+}
+
+func (self *GenericFunction) dispatch(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	typeName := TypeOfName(Car(args))
+
+	self.Mutex.RLock()
+	method, found := self.Methods[typeName]
+	if !found {
+		method, found = self.Methods["default"]
+	}
+	self.Mutex.RUnlock()
+
+	if !found {
+		err = ProcessError(fmt.Sprintf("%s has no method for %s.", self.Name, typeName), env)
+		return
+	}
+	return ApplyWithoutEval(method, args, env)
+}
+
+func DefineGenericImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	name := Car(args)
+	if !SymbolP(name) {
+		err = ProcessError(fmt.Sprintf("define-generic requires a symbol name, but received %s.", String(name)), env)
+		return
+	}
+
+	generic := &GenericFunction{Name: StringValue(name), Methods: make(map[string]*Data)}
+	f := &PrimitiveFunction{Name: generic.Name, Special: false, Body: generic.dispatch}
+	f.parseNumArgs(">=1")
+
+	genericFunctionsMutex.Lock()
+	genericFunctions[f] = generic
+	genericFunctionsMutex.Unlock()
+
+	return env.BindLocallyTo(name, PrimitiveWithNameAndFunc(generic.Name, f))
+}
+
+func DefineMethodImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	name := First(args)
+	if !SymbolP(name) {
+		err = ProcessError(fmt.Sprintf("define-method requires a symbol name, but received %s.", String(name)), env)
+		return
+	}
+
+	f := env.ValueOf(name)
+	var generic *GenericFunction
+	if PrimitiveP(f) {
+		genericFunctionsMutex.RLock()
+		generic = genericFunctions[PrimitiveValue(f)]
+		genericFunctionsMutex.RUnlock()
+	}
+	if generic == nil {
+		err = ProcessError(fmt.Sprintf("define-method requires %s to be a generic function.", String(name)), env)
+		return
+	}
+
+	typeName := Second(args)
+	if !SymbolP(typeName) {
+		err = ProcessError(fmt.Sprintf("define-method requires a type name symbol, but received %s.", String(typeName)), env)
+		return
+	}
+
+	method, err := Eval(Third(args), env)
+	if err != nil {
+		return
+	}
+	if !FunctionOrPrimitiveP(method) {
+		err = ProcessError(fmt.Sprintf("define-method requires a function, but received %s.", String(method)), env)
+		return
+	}
+
+	generic.Mutex.Lock()
+	generic.Methods[StringValue(typeName)] = method
+	generic.Mutex.Unlock()
+
+	return method, nil
+}
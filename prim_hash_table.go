@@ -0,0 +1,389 @@
+// Copyright 2014 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file contains the hash table primitive functions.
+
+package golisp
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+type hashTableEntry struct {
+	Key   *Data
+	Value *Data
+}
+
+// Entries are grouped into buckets by hashKeyFor, and keys within a bucket
+// are compared with equal?.
+type hashBuckets map[string][]hashTableEntry
+
+type HashTable struct {
+	Entries hashBuckets
+	Count   int
+	Mutex   sync.RWMutex
+}
+
+func RegisterHashTablePrimitives() {
+	MakePrimitiveFunction("make-hash-table", "0|1", MakeHashTableImpl)
+	MakePrimitiveFunction("hash-table?", "1", HashTablePImpl)
+	MakePrimitiveFunction("hash-set!", "3", HashSetImpl)
+	MakePrimitiveFunction("hash-ref", "2|3", HashRefImpl)
+	MakePrimitiveFunction("hash-has-key?", "2", HashHasKeyImpl)
+	MakePrimitiveFunction("hash-remove!", "2", HashRemoveImpl)
+	MakePrimitiveFunction("hash-table-size", "1", HashTableSizeImpl)
+	MakePrimitiveFunction("hash-table-keys", "1", HashTableKeysImpl)
+	MakePrimitiveFunction("hash-table-values", "1", HashTableValuesImpl)
+	MakePrimitiveFunction("hash-table->alist", "1", HashTableToAlistImpl)
+	MakePrimitiveFunction("hash-table-copy", "1", HashTableCopyImpl)
+	MakePrimitiveFunction("hash-table-merge!", "2|3", HashTableMergeBangImpl)
+	MakePrimitiveFunction("hash-table-merge", "2|3", HashTableMergeImpl)
+}
+
+func NewHashTable(capacity int) *HashTable {
+	return &HashTable{Entries: make(hashBuckets, capacity)}
+}
+
+func HashTableWithValue(h *HashTable) *Data {
+	return ObjectWithTypeAndValue("HashTable", unsafe.Pointer(h))
+}
+
+func HashTableP(d *Data) bool {
+	return ObjectP(d) && ObjectType(d) == "HashTable"
+}
+
+func HashTableValue(d *Data) *HashTable {
+	return (*HashTable)(ObjectValue(d))
+}
+
+// How deeply hashKeyFor looks into nested lists.
+const hashKeyDepth = 3
+
+// hashKeyFor gives keys that are equal? the same hash key; keys that aren't
+// may share one too, and are told apart by comparing them. Numbers are keyed
+// by their float value, since equal? compares integers and floats that way,
+// and lists by the sorted keys of their elements, since alists are equal
+// whatever the order of their pairs.
+func hashKeyFor(key *Data) string {
+	return hashKeyAtDepth(key, 0)
+}
+
+func hashKeyAtDepth(key *Data, depth int) string {
+	switch {
+	case NilP(key):
+		return "()"
+	case NumberP(key):
+		return "n:" + strconv.FormatFloat(float64(FloatValue(key)), 'g', -1, 32)
+	case StringP(key):
+		return "s:" + StringValue(key)
+	case SymbolP(key):
+		return "y:" + StringValue(key)
+	case BooleanP(key):
+		return String(key)
+	case cellP(key):
+		if depth >= hashKeyDepth {
+			return "l"
+		}
+		seen := make(map[*Data]bool)
+		items := make([]string, 0, 4)
+		c := key
+		for ; cellP(c) && NotNilP(c); c = Cdr(c) {
+			if seen[c] {
+				return "l:cycle"
+			}
+			seen[c] = true
+			items = append(items, hashKeyAtDepth(Car(c), depth+1))
+		}
+		if NotNilP(c) {
+			items = append(items, ". "+hashKeyAtDepth(c, depth+1))
+		}
+		sort.Strings(items)
+		return "l:(" + strings.Join(items, " ") + ")"
+	case FrameP(key):
+		slots := frameSlots(FrameValue(key))
+		names := make([]string, 0, len(slots))
+		for name := range slots {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "f:" + strings.Join(names, " ")
+	case ObjectP(key):
+		return "o:" + ObjectType(key)
+	}
+	return fmt.Sprintf("t:%d", TypeOf(key))
+}
+
+func (self hashBuckets) get(key *Data) (entry hashTableEntry, found bool) {
+	for _, entry = range self[hashKeyFor(key)] {
+		if IsEqualDeep(entry.Key, key) {
+			return entry, true
+		}
+	}
+	return hashTableEntry{}, false
+}
+
+// Setting a key that is already present replaces its value but keeps the
+// original key. Returns whether the key was added.
+func (self hashBuckets) set(key *Data, value *Data) (added bool) {
+	k := hashKeyFor(key)
+	bucket := self[k]
+	for i, entry := range bucket {
+		if IsEqualDeep(entry.Key, key) {
+			bucket[i].Value = value
+			return false
+		}
+	}
+	self[k] = append(bucket, hashTableEntry{Key: key, Value: value})
+	return true
+}
+
+func (self hashBuckets) remove(key *Data) (removed hashTableEntry, found bool) {
+	k := hashKeyFor(key)
+	bucket := self[k]
+	for i, entry := range bucket {
+		if IsEqualDeep(entry.Key, key) {
+			if len(bucket) == 1 {
+				delete(self, k)
+			} else {
+				self[k] = append(bucket[:i:i], bucket[i+1:]...)
+			}
+			return entry, true
+		}
+	}
+	return
+}
+
+// The copy's buckets don't share storage with the original's.
+func (self hashBuckets) copy() hashBuckets {
+	c := make(hashBuckets, len(self))
+	for k, bucket := range self {
+		c[k] = append([]hashTableEntry(nil), bucket...)
+	}
+	return c
+}
+
+func (self *HashTable) Get(key *Data) (value *Data, found bool) {
+	self.Mutex.RLock()
+	entry, found := self.Entries.get(key)
+	self.Mutex.RUnlock()
+	return entry.Value, found
+}
+
+func (self *HashTable) Set(key *Data, value *Data) {
+	self.Mutex.Lock()
+	if self.Entries.set(key, value) {
+		self.Count++
+	}
+	self.Mutex.Unlock()
+}
+
+func (self *HashTable) Remove(key *Data) (found bool) {
+	self.Mutex.Lock()
+	if _, found = self.Entries.remove(key); found {
+		self.Count--
+	}
+	self.Mutex.Unlock()
+	return
+}
+
+func (self *HashTable) Size() int {
+	self.Mutex.RLock()
+	size := self.Count
+	self.Mutex.RUnlock()
+	return size
+}
+
+func (self *HashTable) entries() []hashTableEntry {
+	self.Mutex.RLock()
+	entries := make([]hashTableEntry, 0, self.Count)
+	for _, bucket := range self.Entries {
+		entries = append(entries, bucket...)
+	}
+	self.Mutex.RUnlock()
+	return entries
+}
+
+func (self *HashTable) Copy() *HashTable {
+	self.Mutex.RLock()
+	h := &HashTable{Entries: self.Entries.copy(), Count: self.Count}
+	self.Mutex.RUnlock()
+	return h
+}
+
+func hashTableArg(name string, args *Data, env *SymbolTableFrame) (h *HashTable, err error) {
+	d := Car(args)
+	if !HashTableP(d) {
+		err = ProcessError(fmt.Sprintf("%s requires a hash table as it's first argument, but received %s.", name, String(d)), env)
+		return
+	}
+	return HashTableValue(d), nil
+}
+
+func MakeHashTableImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	capacity := int64(0)
+	if Length(args) == 1 {
+		c := Car(args)
+		if !IntegerP(c) || IntegerValue(c) < 0 {
+			err = ProcessError(fmt.Sprintf("make-hash-table requires a non-negative integer capacity, but received %s.", String(c)), env)
+			return
+		}
+		capacity = IntegerValue(c)
+	}
+	return HashTableWithValue(NewHashTable(int(capacity))), nil
+}
+
+func HashTablePImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return BooleanWithValue(HashTableP(Car(args))), nil
+}
+
+func HashSetImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	h, err := hashTableArg("hash-set!", args, env)
+	if err != nil {
+		return
+	}
+	h.Set(Second(args), Third(args))
+	return Third(args), nil
+}
+
+func HashRefImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	h, err := hashTableArg("hash-ref", args, env)
+	if err != nil {
+		return
+	}
+	value, found := h.Get(Second(args))
+	if !found {
+		return Third(args), nil
+	}
+	return value, nil
+}
+
+func HashHasKeyImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	h, err := hashTableArg("hash-has-key?", args, env)
+	if err != nil {
+		return
+	}
+	_, found := h.Get(Second(args))
+	return BooleanWithValue(found), nil
+}
+
+func HashRemoveImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	h, err := hashTableArg("hash-remove!", args, env)
+	if err != nil {
+		return
+	}
+	return BooleanWithValue(h.Remove(Second(args))), nil
+}
+
+func HashTableSizeImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	h, err := hashTableArg("hash-table-size", args, env)
+	if err != nil {
+		return
+	}
+	return IntegerWithValue(int64(h.Size())), nil
+}
+
+func HashTableKeysImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	h, err := hashTableArg("hash-table-keys", args, env)
+	if err != nil {
+		return
+	}
+	entries := h.entries()
+	keys := make([]*Data, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	return ArrayToList(keys), nil
+}
+
+func HashTableValuesImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	h, err := hashTableArg("hash-table-values", args, env)
+	if err != nil {
+		return
+	}
+	entries := h.entries()
+	values := make([]*Data, 0, len(entries))
+	for _, entry := range entries {
+		values = append(values, entry.Value)
+	}
+	return ArrayToList(values), nil
+}
+
+func HashTableToAlistImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	h, err := hashTableArg("hash-table->alist", args, env)
+	if err != nil {
+		return
+	}
+	for _, entry := range h.entries() {
+		result = Acons(entry.Key, entry.Value, result)
+	}
+	return
+}
+
+func HashTableCopyImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	h, err := hashTableArg("hash-table-copy", args, env)
+	if err != nil {
+		return
+	}
+	return HashTableWithValue(h.Copy()), nil
+}
+
+// Copies the entries of src into dest. When a key is in both, the value from
+// src wins unless a resolver is given, in which case the entry gets the result
+// of applying it to the key, the dest value, and the src value.
+func mergeHashTables(name string, dest *HashTable, args *Data, env *SymbolTableFrame) (err error) {
+	srcObj := Second(args)
+	if !HashTableP(srcObj) {
+		err = ProcessError(fmt.Sprintf("%s requires a hash table as it's second argument, but received %s.", name, String(srcObj)), env)
+		return
+	}
+
+	resolver := Third(args)
+	if Length(args) == 3 && !FunctionOrPrimitiveP(resolver) {
+		err = ProcessError(fmt.Sprintf("%s requires a function as it's third argument, but received %s.", name, String(resolver)), env)
+		return
+	}
+
+	for _, entry := range HashTableValue(srcObj).entries() {
+		value := entry.Value
+		if existing, found := dest.Get(entry.Key); found && Length(args) == 3 {
+			value, err = ApplyWithoutEval(resolver, InternalMakeList(entry.Key, existing, entry.Value), env)
+			if err != nil {
+				return
+			}
+		}
+		dest.Set(entry.Key, value)
+	}
+	return
+}
+
+func HashTableMergeBangImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	dest, err := hashTableArg("hash-table-merge!", args, env)
+	if err != nil {
+		return
+	}
+	err = mergeHashTables("hash-table-merge!", dest, args, env)
+	if err != nil {
+		return
+	}
+	return First(args), nil
+}
+
+func HashTableMergeImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	dest, err := hashTableArg("hash-table-merge", args, env)
+	if err != nil {
+		return
+	}
+	merged := dest.Copy()
+	err = mergeHashTables("hash-table-merge", merged, args, env)
+	if err != nil {
+		return
+	}
+	return HashTableWithValue(merged), nil
+}
@@ -8,14 +8,145 @@
 package golisp
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/SteelSeries/bufrr"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 )
 
+// The source read, read-line, and read-char use when they aren't given a port.
+// The tokenizer used by read looks ahead, so after a read the following
+// read-line or read-char starts after the next token rather than right after
+// the form that was read.
+type inputSource struct {
+	source    io.Reader
+	reader    *bufrr.Reader
+	tokenizer *Tokenizer
+}
+
+// Input is what read, read-line, and read-char read from when they aren't
+// given a port; embedders can call SetInput to supply input. At the end of the
+// input they return the eof object, which eof-object? recognizes, so a loop
+// reading lines can stop when it sees it. with-input-from-string replaces
+// Input while its thunk runs. Change Input with SetInput rather than by
+// assigning to it.
+var Input io.Reader = os.Stdin
+
+var currentInput *inputSource
+var inputMutex sync.Mutex
+
+func newInputSource(r io.Reader) *inputSource {
+	return &inputSource{source: r, reader: bufrr.NewReader(r)}
+}
+
+// SetInput makes r the interpreter's Input. A nil r restores os.Stdin.
+func SetInput(r io.Reader) {
+	if r == nil {
+		r = os.Stdin
+	}
+	swapInput(newInputSource(r))
+}
+
+// Makes source the buffered source for Input, returning the one it replaced.
+func swapInput(source *inputSource) (previous *inputSource) {
+	inputMutex.Lock()
+	defer inputMutex.Unlock()
+	if currentInput == nil {
+		currentInput = newInputSource(Input)
+	}
+	previous = currentInput
+	currentInput = source
+	Input = source.source
+	return
+}
+
+// Returns the buffered source for Input, starting it on first use.
+func input() *inputSource {
+	inputMutex.Lock()
+	defer inputMutex.Unlock()
+	if currentInput == nil {
+		currentInput = newInputSource(Input)
+	}
+	return currentInput
+}
+
+// Output is where the interpreter prints: write-line, write-string, write,
+// newline, and format #t when they aren't given a port and nothing they were
+// called through has its own writer, the REPL, the debugger, tracing, and the
+// Dump methods. It is shared by the whole process; with-output-to-string and
+// a ReplSession with its own writer set a writer on a frame instead, so that
+// only what is evaluated through that frame prints there. Change Output with
+// SetOutput rather than by assigning to it.
+var Output io.Writer = os.Stdout
+var outputMutex sync.RWMutex
+
+// SetOutput directs the interpreter's printing to w, e.g. a buffer to
+// capture it or ioutil.Discard to suppress it. A nil w restores os.Stdout.
+func SetOutput(w io.Writer) {
+	if w == nil {
+		w = os.Stdout
+	}
+	outputMutex.Lock()
+	Output = w
+	outputMutex.Unlock()
+}
+
+func currentOutput() io.Writer {
+	outputMutex.RLock()
+	defer outputMutex.RUnlock()
+	return Output
+}
+
+// Returns the writer of the nearest frame env was called through that has
+// one, or Output if none does.
+func outputFor(env *SymbolTableFrame) io.Writer {
+	for frame := env; frame != nil; frame = frame.Previous {
+		if frame.Output != nil {
+			return frame.Output
+		}
+	}
+	return currentOutput()
+}
+
+// Writes to the output for env, making sure Stdout exists before writing to
+// it, which prevents issues with LDFLAGS="-H windowsgui".
+func writeOutput(s string, env *SymbolTableFrame) (err error) {
+	w := outputFor(env)
+	if w == io.Writer(os.Stdout) {
+		stat, statErr := os.Stdout.Stat()
+		if stat == nil || statErr != nil {
+			return
+		}
+	}
+	_, err = io.WriteString(w, s)
+	return
+}
+
+func (self *inputSource) readForm() (result *Data, err error) {
+	if self.tokenizer == nil {
+		self.tokenizer = NewTokenizer(self.reader)
+	}
+	result, eof, err := parseExpression(self.tokenizer)
+	if err != nil {
+		return
+	}
+	if eof {
+		result = EofObject
+	}
+	return
+}
+
+func (self *inputSource) readChar() (ch rune, eof bool) {
+	ch, _, err := self.reader.ReadRune()
+	return ch, err != nil || ch == -1
+}
+
 func RegisterIOPrimitives() {
 	MakeRestrictedPrimitiveFunction("open-input-file", "1", OpenInputFileImpl)
 	MakeRestrictedPrimitiveFunction("open-output-file", "1|2", OpenOutputFileImpl)
@@ -25,7 +156,11 @@ func RegisterIOPrimitives() {
 	MakePrimitiveFunction("write-string", "1|2", WriteStringImpl)
 	MakePrimitiveFunction("newline", "0|1", NewlineImpl)
 	MakePrimitiveFunction("write", "1|2", WriteImpl)
-	MakePrimitiveFunction("read", "1", ReadImpl)
+	MakePrimitiveFunction("read", "0|1", ReadImpl)
+	MakePrimitiveFunction("read-line", "0", ReadLineImpl)
+	MakePrimitiveFunction("read-char", "0", ReadCharImpl)
+	MakePrimitiveFunction("with-input-from-string", "2", WithInputFromStringImpl)
+	MakeSpecialForm("with-output-to-string", ">=1", WithOutputToStringImpl)
 	MakePrimitiveFunction("eof-object?", "1", EofObjectImpl)
 
 	MakePrimitiveFunction("list-directory", "1|2", ListDirectoryImpl)
@@ -102,76 +237,126 @@ func WriteStringImpl(args *Data, env *SymbolTableFrame) (result *Data, err error
 		return
 	}
 
-	var port *os.File
 	if Length(args) == 1 {
-		port = os.Stdout
-	} else {
-		p := Cadr(args)
-		if !PortP(p) {
-			err = ProcessError("write-string expects its second argument be a port", env)
-			return
-		}
-		port = PortValue(p)
+		err = writeOutput(StringValue(str), env)
+		return
 	}
 
-	_, err = port.WriteString(StringValue(str))
+	p := Cadr(args)
+	if !PortP(p) {
+		err = ProcessError("write-string expects its second argument be a port", env)
+		return
+	}
+	_, err = PortValue(p).WriteString(StringValue(str))
 	return
 }
 
 func WriteImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
-	var port *os.File
-
 	if Length(args) == 1 {
-		port = os.Stdout
-	} else {
-		p := Cadr(args)
-		if !PortP(p) {
-			err = ProcessError("write expects its second argument be a port", env)
-			return
-		}
-		port = PortValue(p)
+		err = writeOutput(String(Car(args)), env)
+		return
 	}
 
-	_, err = port.WriteString(String(Car(args)))
+	p := Cadr(args)
+	if !PortP(p) {
+		err = ProcessError("write expects its second argument be a port", env)
+		return
+	}
+	_, err = PortValue(p).WriteString(String(Car(args)))
 	return
 }
 
 func NewlineImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
-	var port *os.File
-
 	if Length(args) == 0 {
-		port = os.Stdout
-	} else {
-		p := Car(args)
-		if !PortP(p) {
-			err = ProcessError("newline expects its argument be a port", env)
-			return
-		}
-		port = PortValue(p)
+		err = writeOutput("\n", env)
+		return
 	}
 
-	_, err = port.WriteString("\n")
+	p := Car(args)
+	if !PortP(p) {
+		err = ProcessError("newline expects its argument be a port", env)
+		return
+	}
+	_, err = PortValue(p).WriteString("\n")
 	return
 }
 
 func ReadImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
-	var port *os.File
-
 	if Length(args) == 0 {
-		port = os.Stdin
-	} else {
-		p := Car(args)
-		if !PortP(p) {
-			err = ProcessError("read expects its argument be a port", env)
-			return
-		}
-		port = PortValue(p)
+		return input().readForm()
+	}
+
+	p := Car(args)
+	if !PortP(p) {
+		err = ProcessError("read expects its argument be a port", env)
+		return
 	}
 
-	result, err = ParseObjectFromFileInEnv(port, env)
+	result, err = ParseObjectFromFileInEnv(PortValue(p), env)
 	return
 }
 
+func ReadLineImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	line := make([]rune, 0, 80)
+	for {
+		ch, eof := input().readChar()
+		if eof {
+			if len(line) == 0 {
+				return EofObject, nil
+			}
+			break
+		}
+		if ch == '\n' {
+			break
+		}
+		line = append(line, ch)
+	}
+	return StringWithValue(strings.TrimSuffix(string(line), "\r")), nil
+}
+
+func ReadCharImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	ch, eof := input().readChar()
+	if eof {
+		return EofObject, nil
+	}
+	return StringWithValue(string(ch)), nil
+}
+
+func WithInputFromStringImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	str := First(args)
+	if !StringP(str) {
+		err = ProcessError(fmt.Sprintf("with-input-from-string expects a string as its first argument, but received %s.", String(str)), env)
+		return
+	}
+
+	thunk := Second(args)
+	if !FunctionOrPrimitiveP(thunk) {
+		err = ProcessError(fmt.Sprintf("with-input-from-string expects a function as its second argument, but received %s.", String(thunk)), env)
+		return
+	}
+
+	previous := swapInput(newInputSource(strings.NewReader(StringValue(str))))
+	defer swapInput(previous)
+
+	return ApplyWithoutEval(thunk, nil, env)
+}
+
+// Evaluates the body in a frame whose writer is a buffer, returning what was
+// written. Only printing done through that frame is captured, so nested
+// captures each get their own buffer and other goroutines are unaffected.
+func WithOutputToStringImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	var buffer bytes.Buffer
+	localEnv := NewSymbolTableFrameBelow(env, "with-output-to-string")
+	localEnv.Previous = env
+	localEnv.Output = &buffer
+
+	_, err = evaluateBody(args, localEnv)
+	if err != nil {
+		return
+	}
+	return StringWithValue(buffer.String()), nil
+}
+
 func EofObjectImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return BooleanWithValue(IsEqual(Car(args), EofObject)), nil
 }
@@ -196,6 +381,35 @@ func ListDirectoryImpl(args *Data, env *SymbolTableFrame) (result *Data, err err
 	return ArrayToList(names), nil
 }
 
+// Numbers are right aligned, padded on the left to the width.
+func padNumber(s string, width int) string {
+	if len(s) < width {
+		return strings.Repeat(" ", width-len(s)) + s
+	}
+	return s
+}
+
+// Formats a number for ~f. Integers are written from their exact value, since
+// converting them to a float loses digits.
+func formatFixed(n *Data) string {
+	if IntegerP(n) {
+		return strconv.FormatInt(IntegerValue(n), 10)
+	}
+	return strconv.FormatFloat(float64(FloatValue(n)), 'f', -1, 32)
+}
+
+// (format destination control-string arg...) substitutes the arguments into
+// the control string. destination is #f to return the string, #t to write it
+// to stdout, or a port. Each directive is ~, an optional numeric width (or #
+// for the number of remaining arguments, or v to take it from the arguments),
+// an optional @, and one of:
+//   a  the argument as display would print it, padded on the right (left with @)
+//   s  the argument as write would print it, padded the same way
+//   d  an integer, padded on the left
+//   f  an integer or float, padded on the left
+//   %  a newline (width times)
+//   ~  a tilde (width times)
+// A ~ at the end of a line skips the newline and the following whitespace.
 func FormatImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	destination := Car(args)
 	if !BooleanP(destination) && !PortP(destination) {
@@ -211,6 +425,8 @@ func FormatImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	controlString := StringValue(controlStringObj)
 
 	arguments := Cddr(args)
+	// Counted separately since a list of a single nil argument looks like nil.
+	remaining := Length(arguments)
 
 	numberOfSubstitutions := strings.Count(controlString, "~")
 	parts := make([]string, 0, numberOfSubstitutions*2+1)
@@ -227,17 +443,22 @@ func FormatImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 			parts = append(parts, controlString[start:i])
 			i++
 			start = i
-			for unicode.IsDigit(rune(controlString[i])) {
+			for i < len(controlString) && unicode.IsDigit(rune(controlString[i])) {
 				i++
 			}
+			if i == len(controlString) {
+				err = ProcessError("format control string ends in the middle of a substitution", env)
+				return
+			}
 			if i == start {
 				if controlString[i] == '#' {
-					numericArg = Length(arguments)
+					numericArg = remaining
 					i++
 				} else if controlString[i] == 'V' || controlString[i] == 'v' {
-					if IntegerP(Car(arguments)) {
+					if remaining > 0 && IntegerP(Car(arguments)) {
 						numericArg = int(IntegerValue(Car(arguments)))
 						arguments = Cdr(arguments)
+						remaining--
 					} else {
 						err = ProcessError(fmt.Sprintf("format encountered a size argument mismatch at index %d", i), env)
 						return
@@ -253,10 +474,21 @@ func FormatImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 				}
 				numericArg = int(n)
 			}
-			if controlString[i] == '@' {
+			if i < len(controlString) && controlString[i] == '@' {
 				atModifier = true
 				i++
 			}
+			if i == len(controlString) {
+				err = ProcessError("format control string ends in the middle of a substitution", env)
+				return
+			}
+			if strings.ContainsRune("AaSsDdFf", rune(controlString[i])) {
+				if remaining == 0 {
+					err = ProcessError(fmt.Sprintf("format ran out of arguments for the substitution at index %d", i), env)
+					return
+				}
+				remaining--
+			}
 			switch controlString[i] {
 			case 'A', 'a':
 				substitution = PrintString(Car(arguments))
@@ -292,6 +524,24 @@ func FormatImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 				arguments = Cdr(arguments)
 				start = i + 1
 
+			case 'D', 'd':
+				if !IntegerP(Car(arguments)) {
+					err = ProcessError(fmt.Sprintf("format expected an integer for ~d, but received %s", String(Car(arguments))), env)
+					return
+				}
+				parts = append(parts, padNumber(strconv.FormatInt(IntegerValue(Car(arguments)), 10), numericArg))
+				arguments = Cdr(arguments)
+				start = i + 1
+
+			case 'F', 'f':
+				if !NumberP(Car(arguments)) {
+					err = ProcessError(fmt.Sprintf("format expected a number for ~f, but received %s", String(Car(arguments))), env)
+					return
+				}
+				parts = append(parts, padNumber(formatFixed(Car(arguments)), numericArg))
+				arguments = Cdr(arguments)
+				start = i + 1
+
 			case '%':
 				if numericArg > 0 {
 					parts = append(parts, strings.Repeat("\n", numericArg))
@@ -330,7 +580,7 @@ func FormatImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 		parts = append(parts, controlString[start:i])
 	}
 
-	if i < len(controlString) || !NilP(arguments) {
+	if i < len(controlString) || remaining > 0 {
 		err = ProcessError("number of replacements in the control string and number of arguments must be equal", env)
 		return
 	}
@@ -341,11 +591,7 @@ func FormatImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 		port := PortValue(destination)
 		_, err = port.WriteString(combinedString)
 	} else if BooleanValue(destination) {
-		// Make sure Stdout exists before writing to it, prevents issues with LDFLAGS="-H windowsgui"
-		stat, statErr := os.Stdout.Stat()
-		if stat != nil && statErr == nil {
-			_, err = os.Stdout.WriteString(combinedString)
-		}
+		err = writeOutput(combinedString, env)
 	} else {
 		result = StringWithValue(combinedString)
 	}
@@ -267,10 +267,12 @@ func NthImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return Nth(col, int(IntegerValue(count))), nil
 }
 
+// A count past the end of the list or bytearray takes all of it.
 func TakeImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	n := Car(args)
-	if !IntegerP(n) {
-		err = ProcessError("take requires a number as its first argument.", env)
+	if !IntegerP(n) || IntegerValue(n) < 0 {
+		err = ProcessError("take requires a non-negative number as its first argument.", env)
+		return
 	}
 	size := int(IntegerValue(n))
 
@@ -300,10 +302,12 @@ func TakeImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return
 }
 
+// A count past the end of the list or bytearray drops all of it.
 func DropImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	n := Car(args)
-	if !IntegerP(n) {
-		err = ProcessError("drop requires a number as its first argument.", env)
+	if !IntegerP(n) || IntegerValue(n) < 0 {
+		err = ProcessError("drop requires a non-negative number as its first argument.", env)
+		return
 	}
 	size := int(IntegerValue(n))
 
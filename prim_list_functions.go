@@ -18,6 +18,8 @@ func RegisterListFunctionsPrimitives() {
 	MakePrimitiveFunction("any", ">=2", AnyImpl)
 	MakePrimitiveFunction("every", ">=2", EveryImpl)
 	MakePrimitiveFunction("reduce", "3", ReduceImpl)
+	MakePrimitiveFunction("fold-left", "3", FoldLeftImpl)
+	MakePrimitiveFunction("fold-right", "3", FoldRightImpl)
 	MakePrimitiveFunction("filter", "2", FilterImpl)
 	MakePrimitiveFunction("remove", "2", RemoveImpl)
 	MakePrimitiveFunction("memq", "2", MemqImpl)
@@ -26,6 +28,9 @@ func RegisterListFunctionsPrimitives() {
 	MakePrimitiveFunction("memp", "2", FindTailImpl)
 	MakePrimitiveFunction("find-tail", "2", FindTailImpl)
 	MakePrimitiveFunction("find", "2", FindImpl)
+	MakePrimitiveFunction("take-while", "2", TakeWhileImpl)
+	MakePrimitiveFunction("drop-while", "2", DropWhileImpl)
+	MakePrimitiveFunction("span", "2", SpanImpl)
 }
 
 func intMin(x, y int64) int64 {
@@ -227,7 +232,7 @@ func ReduceImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	col := Third(args)
 
 	if !ListP(col) {
-		err = ProcessError("map needs a list as its third argument", env)
+		err = ProcessError("reduce needs a list as its third argument", env)
 		return
 	}
 
@@ -250,6 +255,55 @@ func ReduceImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return
 }
 
+// Checks the arguments of fold-left and fold-right, returning the list's elements.
+func foldArgs(name string, args *Data, env *SymbolTableFrame) (f *Data, items []*Data, err error) {
+	f = First(args)
+	if !FunctionOrPrimitiveP(f) {
+		err = ProcessError(fmt.Sprintf("%s needs a function as its first argument, but got %s.", name, String(f)), env)
+		return
+	}
+	col := Third(args)
+	if !ListP(col) {
+		err = ProcessError(fmt.Sprintf("%s needs a list as its third argument, but got %s.", name, String(col)), env)
+		return
+	}
+	return f, ToArray(col), nil
+}
+
+// (fold-left f initial list) threads an accumulator, starting with initial,
+// through the list from head to tail: (f (f (f initial a) b) c).
+func FoldLeftImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	f, items, err := foldArgs("fold-left", args, env)
+	if err != nil {
+		return
+	}
+	result = Second(args)
+	for _, item := range items {
+		result, err = ApplyWithoutEval(f, InternalMakeList(result, item), env)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// (fold-right f initial list) threads an accumulator, starting with initial,
+// through the list from tail to head: (f a (f b (f c initial))).
+func FoldRightImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	f, items, err := foldArgs("fold-right", args, env)
+	if err != nil {
+		return
+	}
+	result = Second(args)
+	for i := len(items) - 1; i >= 0; i-- {
+		result, err = ApplyWithoutEval(f, InternalMakeList(items[i], result), env)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
 func FilterImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	f := First(args)
 	if !FunctionOrPrimitiveP(f) {
@@ -386,3 +440,69 @@ func FindImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 
 	return LispFalse, nil
 }
+
+// Splits a list at the first element that doesn't satisfy the predicate,
+// returning the elements before it and the tail starting with it. The
+// predicate isn't applied to anything past that element.
+func splitWhile(name string, args *Data, env *SymbolTableFrame) (prefix []*Data, rest *Data, err error) {
+	f := First(args)
+	if !FunctionOrPrimitiveP(f) {
+		err = ProcessError(fmt.Sprintf("%s needs a function as its first argument, but got %s.", name, String(f)), env)
+		return
+	}
+
+	l := Second(args)
+	if !ListP(l) {
+		err = ProcessError(fmt.Sprintf("%s needs a list as its second argument, but got %s.", name, String(l)), env)
+		return
+	}
+
+	prefix = make([]*Data, 0, Length(l))
+	var v *Data
+	for rest = l; NotNilP(rest); rest = Cdr(rest) {
+		v, err = ApplyWithoutEval(f, InternalMakeList(Car(rest)), env)
+		if err != nil {
+			return
+		}
+		if !BooleanP(v) {
+			err = ProcessError(fmt.Sprintf("%s needs a predicate function as its first argument.", name), env)
+			return
+		}
+		if !BooleanValue(v) {
+			return
+		}
+		prefix = append(prefix, Car(rest))
+	}
+	return
+}
+
+func TakeWhileImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	prefix, _, err := splitWhile("take-while", args, env)
+	if err != nil {
+		return
+	}
+	return ArrayToList(prefix), nil
+}
+
+func DropWhileImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	_, rest, err := splitWhile("drop-while", args, env)
+	if err != nil {
+		return
+	}
+	if rest == nil {
+		return EmptyCons(), nil
+	}
+	return rest, nil
+}
+
+// Returns the results of take-while and drop-while as two values.
+func SpanImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	prefix, rest, err := splitWhile("span", args, env)
+	if err != nil {
+		return
+	}
+	if rest == nil {
+		rest = EmptyCons()
+	}
+	return ValuesWithArray([]*Data{ArrayToList(prefix), rest}), nil
+}
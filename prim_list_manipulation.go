@@ -7,13 +7,20 @@
 
 package golisp
 
+import (
+	"fmt"
+)
+
 func RegisterListManipulationPrimitives() {
 	MakePrimitiveFunction("list", "*", ListImpl)
 	MakePrimitiveFunction("make-list", "1|2", MakeListImpl)
 	MakePrimitiveFunction("length", "1", ListLengthImpl)
+	MakePrimitiveFunction("length+", "1", ListLengthPlusImpl)
+	MakePrimitiveFunction("length>=?", "2", ListLengthAtLeastImpl)
 	MakePrimitiveFunction("cons", "2", ConsImpl)
 	MakePrimitiveFunction("cons*", ">=1", ConsStarImpl)
 	MakePrimitiveFunction("reverse", "1", ReverseImpl)
+	MakePrimitiveFunction("reverse!", "1", ReverseBangImpl)
 	MakePrimitiveFunction("flatten", "1", FlattenImpl)
 	MakePrimitiveFunction("flatten*", "1", RecursiveFlattenImpl)
 	MakePrimitiveFunction("append", "*", AppendImpl)
@@ -22,6 +29,7 @@ func RegisterListManipulationPrimitives() {
 	MakePrimitiveFunction("partition", "2", PartitionImpl)
 	MakePrimitiveFunction("sublist", "3", SublistImpl)
 	MakePrimitiveFunction("sort", "2", SortImpl)
+	MakePrimitiveFunction("stable-sort", "2", StableSortImpl)
 }
 
 func MakeListImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -67,6 +75,55 @@ func ListLengthImpl(args *Data, env *SymbolTableFrame) (result *Data, err error)
 	return IntegerWithValue(int64(Length(Car(args)))), nil
 }
 
+// Returns the length of a proper list, or false for a dotted or circular list.
+func ListLengthPlusImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	slow := Car(args)
+	fast := slow
+	length := 0
+	for NotNilP(fast) {
+		if !ListP(fast) {
+			return LispFalse, nil
+		}
+		fast = Cdr(fast)
+		length++
+		if NilP(fast) {
+			break
+		}
+		if !ListP(fast) {
+			return LispFalse, nil
+		}
+		fast = Cdr(fast)
+		length++
+		slow = Cdr(slow)
+		if fast == slow {
+			return LispFalse, nil
+		}
+	}
+	return IntegerWithValue(int64(length)), nil
+}
+
+// Only walks as far as needed, so it is cheap on long (or circular) lists.
+func ListLengthAtLeastImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	l := First(args)
+	if !ListP(l) {
+		err = ProcessError(fmt.Sprintf("length>=? requires a list as it's first argument, but received %s.", String(l)), env)
+		return
+	}
+
+	n := Second(args)
+	if !IntegerP(n) {
+		err = ProcessError(fmt.Sprintf("length>=? requires an integer as it's second argument, but received %s.", String(n)), env)
+		return
+	}
+
+	limit := IntegerValue(n)
+	count := int64(0)
+	for c := l; count < limit && NotNilP(c) && ListP(c); c = Cdr(c) {
+		count++
+	}
+	return BooleanWithValue(count >= limit), nil
+}
+
 func ConsImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	car := Car(args)
 	cdr := Cadr(args)
@@ -77,6 +134,30 @@ func ReverseImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return Reverse(Car(args)), nil
 }
 
+// Reverses a list in place by relinking its cells, returning the new head.
+// Anything else sharing the list will see it mangled.
+func ReverseBangImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	l := Car(args)
+	if !ListP(l) {
+		return l, nil
+	}
+
+	for c := l; NotNilP(c); c = Cdr(c) {
+		if !PairP(c) {
+			err = ProcessError(fmt.Sprintf("reverse! requires a proper list, but received %s.", String(l)), env)
+			return
+		}
+	}
+
+	var next *Data
+	for c := l; NotNilP(c); c = next {
+		next = Cdr(c)
+		ConsValue(c).Cdr = result
+		result = c
+	}
+	return
+}
+
 func FlattenImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return Flatten(Car(args))
 }
@@ -252,32 +333,32 @@ func SublistImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 }
 
 func mergeCompare(a *Data, b *Data, proc *Data, env *SymbolTableFrame) (result bool, err error) {
-	if FunctionP(proc) {
-		b, err := FunctionValue(proc).Apply(InternalMakeList(a, b), env)
-		if err == nil {
-			result = BooleanValue(b)
-		}
-	} else {
-		b, err := PrimitiveValue(proc).Apply(InternalMakeList(a, b), env)
-		if err == nil {
-			result = BooleanValue(b)
-		}
+	r, err := ApplyWithoutEval(proc, InternalMakeList(a, b), env)
+	if err != nil {
+		return
 	}
-	return
+	return BooleanValue(r), nil
 }
 
-func merge(a []*Data, b []*Data, proc *Data, env *SymbolTableFrame) (result []*Data, err error) {
+// A stable merge only takes from b when its item must come before a's, so
+// items the comparison considers equal keep their relative order.
+func merge(a []*Data, b []*Data, proc *Data, stable bool, env *SymbolTableFrame) (result []*Data, err error) {
 	var r = make([]*Data, len(a)+len(b))
 	var i = 0
 	var j = 0
-	var comparison = false
+	var takeA = false
 
 	for i < len(a) && j < len(b) {
-		comparison, err = mergeCompare(a[i], b[j], proc, env)
+		if stable {
+			takeA, err = mergeCompare(b[j], a[i], proc, env)
+			takeA = !takeA
+		} else {
+			takeA, err = mergeCompare(a[i], b[j], proc, env)
+		}
 		if err != nil {
 			return
 		}
-		if comparison {
+		if takeA {
 			r[i+j] = a[i]
 			i++
 		} else {
@@ -298,43 +379,52 @@ func merge(a []*Data, b []*Data, proc *Data, env *SymbolTableFrame) (result []*D
 	return r, nil
 }
 
-func mergesort(items []*Data, proc *Data, env *SymbolTableFrame) (result []*Data, err error) {
+func mergesort(items []*Data, proc *Data, stable bool, env *SymbolTableFrame) (result []*Data, err error) {
 	if len(items) < 2 {
 		return items, nil
 	}
 
 	var middle = len(items) / 2
 
-	a, err := mergesort(items[:middle], proc, env)
+	a, err := mergesort(items[:middle], proc, stable, env)
 	if err != nil {
 		return
 	}
 
-	b, err := mergesort(items[middle:], proc, env)
+	b, err := mergesort(items[middle:], proc, stable, env)
 	if err != nil {
 		return
 	}
 
-	return merge(a, b, proc, env)
+	return merge(a, b, proc, stable, env)
 }
 
-func SortImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+func sortCommon(name string, args *Data, stable bool, env *SymbolTableFrame) (result *Data, err error) {
 	coll := Car(args)
 	if !ListP(coll) {
-		err = ProcessError("sort requires a list as it's first argument.", env)
+		err = ProcessError(fmt.Sprintf("%s requires a list as it's first argument.", name), env)
 		return
 	}
 
 	proc := Cadr(args)
 	if !FunctionOrPrimitiveP(proc) {
-		err = ProcessError("sort requires a function or primitive as it's second argument.", env)
+		err = ProcessError(fmt.Sprintf("%s requires a function or primitive as it's second argument.", name), env)
 		return
 	}
 
-	sorted, err := mergesort(ToArray(coll), proc, env)
+	sorted, err := mergesort(ToArray(coll), proc, stable, env)
 	if err != nil {
 		return
 	}
 
 	return ArrayToList(sorted), nil
 }
+
+func SortImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return sortCommon("sort", args, false, env)
+}
+
+// Items the comparison considers equal keep the order they had in the list.
+func StableSortImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return sortCommon("stable-sort", args, true, env)
+}
@@ -17,6 +17,8 @@ func RegisterMacroPrimitives() {
 	MakeSpecialForm("unquote", "1", UnquoteImpl)
 	MakeSpecialForm("unquote-splicing", "1", UnquoteSplicingImpl)
 	MakeSpecialForm("expand", ">=1", ExpandImpl)
+	MakePrimitiveFunction("macroexpand-1", "1", Macroexpand1Impl)
+	MakePrimitiveFunction("macroexpand", "1", MacroexpandImpl)
 }
 
 func QuoteImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -125,3 +127,36 @@ func ExpandImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	}
 	return MacroValue(n).Expand(Cdr(args), env)
 }
+
+// Expands form once if it is a call to a macro, reporting whether it was.
+func macroexpand1(form *Data, env *SymbolTableFrame) (result *Data, expanded bool, err error) {
+	if !PairP(form) || NilP(form) || !SymbolP(Car(form)) {
+		return form, false, nil
+	}
+	m := env.ValueOf(Car(form))
+	if !MacroP(m) {
+		return form, false, nil
+	}
+	result, err = MacroValue(m).Expand(Cdr(form), env)
+	return result, true, err
+}
+
+// (macroexpand-1 form) returns the expansion of a macro call without
+// evaluating it, or form itself if it isn't one.
+func Macroexpand1Impl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, _, err = macroexpand1(Car(args), env)
+	return
+}
+
+// (macroexpand form) expands form until it is no longer a macro call. Only
+// the form itself is expanded, not the forms within it.
+func MacroexpandImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result = Car(args)
+	for expanded := true; expanded; {
+		result, expanded, err = macroexpand1(result, env)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
@@ -10,7 +10,10 @@ package golisp
 import (
 	"fmt"
 	"math"
+	"math/big"
 	"math/rand"
+	"strconv"
+	"strings"
 )
 
 func RegisterMathPrimitives() {
@@ -20,19 +23,31 @@ func RegisterMathPrimitives() {
 	MakePrimitiveFunction("/", "*", QuotientImpl)
 	MakePrimitiveFunction("succ", "1", IncrementImpl)
 	MakePrimitiveFunction("pred", "1", DecrementImpl)
-	MakePrimitiveFunction("quotient", "*", QuotientImpl)
+	MakePrimitiveFunction("quotient", "2", IntegerQuotientImpl)
 	MakePrimitiveFunction("%", "2", RemainderImpl)
-	MakePrimitiveFunction("modulo", "2", RemainderImpl)
+	MakePrimitiveFunction("rem", "2", RemainderImpl)
+	MakePrimitiveFunction("remainder", "2", RemainderImpl)
+	MakePrimitiveFunction("mod", "2", ModuloImpl)
+	MakePrimitiveFunction("modulo", "2", ModuloImpl)
 	MakePrimitiveFunction("random-byte", "0", RandomByteImpl)
 	MakePrimitiveFunction("interval", "1|2|3", IntervalImpl)
+	MakePrimitiveFunction("iota", "1|2|3", IotaImpl)
+	MakePrimitiveFunction("range", "1|2|3", RangeImpl)
 	MakePrimitiveFunction("integer", "1", ToIntImpl)
+	MakePrimitiveFunction("big", "1", ToBigIntegerImpl)
 	MakePrimitiveFunction("float", "1", ToFloatImpl)
 	MakePrimitiveFunction("number->string", "1|2", NumberToStringImpl)
 	MakePrimitiveFunction("string->number", "1|2", StringToNumberImpl)
+	MakePrimitiveFunction("char->digit", "1|2", CharToDigitImpl)
+	MakePrimitiveFunction("digit->char", "1|2", DigitToCharImpl)
+	MakePrimitiveFunction("number->formatted-string", "1|2", NumberToFormattedStringImpl)
 	MakePrimitiveFunction("min", "1", MinImpl)
 	MakePrimitiveFunction("max", "1", MaxImpl)
 	MakePrimitiveFunction("floor", "1", FloorImpl)
+	MakePrimitiveFunction("floor/", "2", FloorDivideImpl)
 	MakePrimitiveFunction("ceiling", "1", CeilingImpl)
+	MakePrimitiveFunction("ceil", "1", CeilingImpl)
+	MakePrimitiveFunction("round", "1", RoundImpl)
 	MakePrimitiveFunction("abs", "1", AbsImpl)
 	MakePrimitiveFunction("zero?", "1", ZeroImpl)
 	MakePrimitiveFunction("positive?", "1", PositiveImpl)
@@ -46,6 +61,9 @@ func RegisterMathPrimitives() {
 	MakePrimitiveFunction("float->bits", "1", FloatToBitsImpl)
 	MakePrimitiveFunction("bits->float", "1", BitsToFloatImpl)
 
+	// NaN is allowed through, giving NaN as for the other functions.
+	positive := func(x float64) bool { return !(x <= 0) }
+
 	makeUnaryFloatFunction("acos", math.Acos)
 	makeUnaryFloatFunction("acosh", math.Acosh)
 	makeUnaryFloatFunction("asin", math.Asin)
@@ -63,14 +81,14 @@ func RegisterMathPrimitives() {
 	makeUnaryFloatFunction("gamma", math.Gamma)
 	makeUnaryFloatFunction("j0", math.J0)
 	makeUnaryFloatFunction("j1", math.J1)
-	makeUnaryFloatFunction("log", math.Log)
-	makeUnaryFloatFunction("log10", math.Log10)
-	makeUnaryFloatFunction("log1p", math.Log1p)
-	makeUnaryFloatFunction("log2", math.Log2)
+	makeUnaryFloatFunctionOnDomain("log", math.Log, "positive numbers", positive)
+	makeUnaryFloatFunctionOnDomain("log10", math.Log10, "positive numbers", positive)
+	makeUnaryFloatFunctionOnDomain("log1p", math.Log1p, "numbers greater than -1", func(x float64) bool { return !(x <= -1) })
+	makeUnaryFloatFunctionOnDomain("log2", math.Log2, "positive numbers", positive)
 	makeUnaryFloatFunction("logb", math.Logb)
 	makeUnaryFloatFunction("sin", math.Sin)
 	makeUnaryFloatFunction("sinh", math.Sinh)
-	makeUnaryFloatFunction("sqrt", math.Sqrt)
+	makeUnaryFloatFunctionOnDomain("sqrt", math.Sqrt, "non-negative numbers", func(x float64) bool { return !(x < 0) })
 	makeUnaryFloatFunction("tan", math.Tan)
 	makeUnaryFloatFunction("tanh", math.Tanh)
 	makeUnaryFloatFunction("y0", math.Y0)
@@ -93,6 +111,12 @@ func RegisterMathPrimitives() {
 }
 
 func makeUnaryFloatFunction(name string, f func(float64) float64) {
+	makeUnaryFloatFunctionOnDomain(name, f, "", nil)
+}
+
+// Arguments for which inDomain is false are an error, rather than giving NaN
+// or an infinity. domain describes the valid arguments for the message.
+func makeUnaryFloatFunctionOnDomain(name string, f func(float64) float64, domain string, inDomain func(float64) bool) {
 	primFunc := func(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 		valObj := Car(args)
 
@@ -102,6 +126,10 @@ func makeUnaryFloatFunction(name string, f func(float64) float64) {
 		}
 
 		val := FloatValue(valObj)
+		if inDomain != nil && !inDomain(float64(val)) {
+			err = ProcessError(fmt.Sprintf("%s is only defined for %s, got %s", name, domain, String(valObj)), env)
+			return
+		}
 
 		ret := f(float64(val))
 
@@ -126,23 +154,21 @@ func intSgn(a int64) int64 {
 }
 
 func IncrementImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
-	if !IntegerP(Car(args)) {
+	if !ExactIntegerP(Car(args)) {
 		err = ProcessError("1+ requires an integer argument", env)
 		return
 	}
 
-	val := IntegerValue(Car(args))
-	return IntegerWithValue(val + 1), nil
+	return addInts(InternalMakeList(Car(args), IntegerWithValue(1)), env)
 }
 
 func DecrementImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
-	if !IntegerP(Car(args)) {
+	if !ExactIntegerP(Car(args)) {
 		err = ProcessError("1- requires an integer argument", env)
 		return
 	}
 
-	val := IntegerValue(Car(args))
-	return IntegerWithValue(val - 1), nil
+	return subtractInts(InternalMakeList(Car(args), IntegerWithValue(1)), env)
 }
 
 func addFloats(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -153,14 +179,33 @@ func addFloats(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return FloatWithValue(acc), nil
 }
 
+// Integer arithmetic is done with big integers if any argument is one, or if
+// the result would overflow int64, and then gives a big integer.
+
 func addInts(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	var acc int64 = 0
 	for c := args; NotNilP(c); c = Cdr(c) {
-		acc += IntegerValue(Car(c))
+		if BigIntegerP(Car(c)) {
+			return addBigIntegers(args), nil
+		}
+		n := IntegerValue(Car(c))
+		sum := acc + n
+		if (acc^sum)&(n^sum) < 0 {
+			return addBigIntegers(args), nil
+		}
+		acc = sum
 	}
 	return IntegerWithValue(acc), nil
 }
 
+func addBigIntegers(args *Data) *Data {
+	acc := new(big.Int)
+	for c := args; NotNilP(c); c = Cdr(c) {
+		acc.Add(acc, BigIntegerValue(Car(c)))
+	}
+	return BigIntegerWithValue(acc)
+}
+
 func anyFloats(args *Data, env *SymbolTableFrame) (result bool, err error) {
 	for c := args; NotNilP(c); c = Cdr(c) {
 		if !NumberP(Car(c)) {
@@ -187,13 +232,32 @@ func AddImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 }
 
 func subtractInts(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	if BigIntegerP(Car(args)) {
+		return subtractBigIntegers(args), nil
+	}
 	acc := IntegerValue(Car(args))
 	for c := Cdr(args); NotNilP(c); c = Cdr(c) {
-		acc -= IntegerValue(Car(c))
+		if BigIntegerP(Car(c)) {
+			return subtractBigIntegers(args), nil
+		}
+		n := IntegerValue(Car(c))
+		diff := acc - n
+		if (acc^n)&(acc^diff) < 0 {
+			return subtractBigIntegers(args), nil
+		}
+		acc = diff
 	}
 	return IntegerWithValue(acc), nil
 }
 
+func subtractBigIntegers(args *Data) *Data {
+	acc := new(big.Int).Set(BigIntegerValue(Car(args)))
+	for c := Cdr(args); NotNilP(c); c = Cdr(c) {
+		acc.Sub(acc, BigIntegerValue(Car(c)))
+	}
+	return BigIntegerWithValue(acc)
+}
+
 func subtractFloats(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	acc := FloatValue(Car(args))
 	for c := Cdr(args); NotNilP(c); c = Cdr(c) {
@@ -217,11 +281,27 @@ func SubtractImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 func multiplyInts(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	var acc int64 = 1
 	for c := args; NotNilP(c); c = Cdr(c) {
-		acc *= IntegerValue(Car(c))
+		if BigIntegerP(Car(c)) {
+			return multiplyBigIntegers(args), nil
+		}
+		n := IntegerValue(Car(c))
+		product := acc * n
+		if acc != 0 && (product/acc != n || (acc == -1 && n == math.MinInt64)) {
+			return multiplyBigIntegers(args), nil
+		}
+		acc = product
 	}
 	return IntegerWithValue(acc), nil
 }
 
+func multiplyBigIntegers(args *Data) *Data {
+	acc := big.NewInt(1)
+	for c := args; NotNilP(c); c = Cdr(c) {
+		acc.Mul(acc, BigIntegerValue(Car(c)))
+	}
+	return BigIntegerWithValue(acc)
+}
+
 func multiplyFloats(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	var acc float32 = 1.0
 	for c := args; NotNilP(c); c = Cdr(c) {
@@ -243,12 +323,19 @@ func MultiplyImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 }
 
 func quotientInts(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	for c := args; NotNilP(c); c = Cdr(c) {
+		if BigIntegerP(Car(c)) {
+			return quotientBigIntegers(args, env)
+		}
+	}
 	acc := IntegerValue(Car(args))
 	for c := Cdr(args); NotNilP(c); c = Cdr(c) {
 		v := IntegerValue(Car(c))
 		if v == 0 {
 			err = ProcessError(fmt.Sprintf("Quotent: %s -> Divide by zero.", String(args)), env)
 			return
+		} else if acc == math.MinInt64 && v == -1 {
+			return quotientBigIntegers(args, env)
 		} else {
 			acc /= v
 		}
@@ -256,6 +343,19 @@ func quotientInts(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return IntegerWithValue(acc), nil
 }
 
+func quotientBigIntegers(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	acc := new(big.Int).Set(BigIntegerValue(Car(args)))
+	for c := Cdr(args); NotNilP(c); c = Cdr(c) {
+		v := BigIntegerValue(Car(c))
+		if v.Sign() == 0 {
+			err = ProcessError(fmt.Sprintf("Quotent: %s -> Divide by zero.", String(args)), env)
+			return
+		}
+		acc.Quo(acc, v)
+	}
+	return BigIntegerWithValue(acc), nil
+}
+
 func quotientFloats(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	var acc float32 = FloatValue(Car(args))
 	for c := Cdr(args); NotNilP(c); c = Cdr(c) {
@@ -282,20 +382,75 @@ func QuotientImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	}
 }
 
-func RemainderImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
-	dividend := Car(args)
-	if !IntegerP(dividend) {
-		err = ProcessError(fmt.Sprintf("%/modulo expected an integer first arg, received %s", String(dividend)), env)
+// Checks the arguments of the integer division primitives.
+func integerDivisionArgs(name string, args *Data, env *SymbolTableFrame) (dividend *Data, divisor *Data, err error) {
+	dividend, divisor = First(args), Second(args)
+	if !ExactIntegerP(dividend) {
+		err = ProcessError(fmt.Sprintf("%s expected an integer first arg, received %s", name, String(dividend)), env)
+		return
+	}
+	if !ExactIntegerP(divisor) {
+		err = ProcessError(fmt.Sprintf("%s expected an integer second arg, received %s", name, String(divisor)), env)
 		return
 	}
+	if BigIntegerValue(divisor).Sign() == 0 {
+		err = ProcessError(fmt.Sprintf("%s: %s -> Divide by zero.", name, String(args)), env)
+	}
+	return
+}
+
+// Integer division is done with big integers when either argument is one, or
+// when the int64 quotient would overflow.
+func bigIntegerDivisionP(dividend *Data, divisor *Data) bool {
+	return BigIntegerP(dividend) || BigIntegerP(divisor) || (IntegerValue(dividend) == math.MinInt64 && IntegerValue(divisor) == -1)
+}
+
+// The integer division primitives differ for negative operands:
+//   quotient   truncates toward zero:           (quotient -7 2) => -3
+//   remainder  has the sign of the dividend:   (remainder -7 2) => -1
+//   modulo     has the sign of the divisor:    (modulo -7 2) => 1
+// % and rem are remainder, and mod is modulo.
+
+func IntegerQuotientImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	dividend, divisor, err := integerDivisionArgs("quotient", args, env)
+	if err != nil {
+		return
+	}
+	if bigIntegerDivisionP(dividend, divisor) {
+		return BigIntegerWithValue(new(big.Int).Quo(BigIntegerValue(dividend), BigIntegerValue(divisor))), nil
+	}
+	return IntegerWithValue(IntegerValue(dividend) / IntegerValue(divisor)), nil
+}
 
-	divisor := Cadr(args)
-	if !IntegerP(divisor) {
-		err = ProcessError(fmt.Sprintf("%/modulo expected an integer second arg, received %s", String(divisor)), env)
+func RemainderImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	dividend, divisor, err := integerDivisionArgs("remainder", args, env)
+	if err != nil {
 		return
 	}
+	if bigIntegerDivisionP(dividend, divisor) {
+		return BigIntegerWithValue(new(big.Int).Rem(BigIntegerValue(dividend), BigIntegerValue(divisor))), nil
+	}
+	return IntegerWithValue(IntegerValue(dividend) % IntegerValue(divisor)), nil
+}
 
-	val := IntegerValue(dividend) % IntegerValue(divisor)
+func ModuloImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	dividend, divisor, err := integerDivisionArgs("modulo", args, env)
+	if err != nil {
+		return
+	}
+	if bigIntegerDivisionP(dividend, divisor) {
+		d := BigIntegerValue(divisor)
+		val := new(big.Int).Rem(BigIntegerValue(dividend), d)
+		if val.Sign() != 0 && (val.Sign() < 0) != (d.Sign() < 0) {
+			val.Add(val, d)
+		}
+		return BigIntegerWithValue(val), nil
+	}
+	d := IntegerValue(divisor)
+	val := IntegerValue(dividend) % d
+	if val != 0 && (val < 0) != (d < 0) {
+		val += d
+	}
 	return IntegerWithValue(val), nil
 }
 
@@ -357,6 +512,77 @@ func IntervalImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return
 }
 
+// (iota count [start [step]]) returns count numbers beginning with start (default 0).
+func IotaImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	countObj := First(args)
+	if !IntegerP(countObj) || IntegerValue(countObj) < 0 {
+		err = ProcessError(fmt.Sprintf("iota requires a non-negative integer count, but received %s.", String(countObj)), env)
+		return
+	}
+	count := IntegerValue(countObj)
+
+	start := IntegerWithValue(0)
+	step := IntegerWithValue(1)
+	if Length(args) > 1 {
+		start = Second(args)
+	}
+	if Length(args) > 2 {
+		step = Third(args)
+	}
+	if !NumberP(start) || !NumberP(step) {
+		err = ProcessError(fmt.Sprintf("iota requires numeric start and step values, but received %s and %s.", String(start), String(step)), env)
+		return
+	}
+
+	items := make([]*Data, 0, count)
+	if IntegerP(start) && IntegerP(step) {
+		for i := int64(0); i < count; i++ {
+			items = append(items, IntegerWithValue(IntegerValue(start)+i*IntegerValue(step)))
+		}
+	} else {
+		for i := int64(0); i < count; i++ {
+			items = append(items, FloatWithValue(FloatValue(start)+float32(i)*FloatValue(step)))
+		}
+	}
+	return ArrayToList(items), nil
+}
+
+// (range end), (range start end), or (range start end step) lists the
+// integers from start (default 0) up to but not including end, counting by
+// step (default 1). A negative step counts down, stopping above end; a step
+// in the opposite direction to end gives an empty list.
+func RangeImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	for c := args; NotNilP(c); c = Cdr(c) {
+		if !IntegerP(Car(c)) {
+			err = ProcessError(fmt.Sprintf("range requires integer arguments, but received %s.", String(Car(c))), env)
+			return
+		}
+	}
+
+	var start, end, step int64 = 0, IntegerValue(First(args)), 1
+	if Length(args) > 1 {
+		start, end = IntegerValue(First(args)), IntegerValue(Second(args))
+	}
+	if Length(args) > 2 {
+		step = IntegerValue(Third(args))
+	}
+	if step == 0 {
+		err = ProcessError("range requires a non-zero step.", env)
+		return
+	}
+
+	var items []*Data
+	for i := start; (step > 0 && i < end) || (step < 0 && i > end); i += step {
+		items = append(items, IntegerWithValue(i))
+		// Stop rather than wrap when the next value is past the limits of an int64.
+		if (step > 0 && i > math.MaxInt64-step) || (step < 0 && i < math.MinInt64-step) {
+			break
+		}
+	}
+	return ArrayToList(items), nil
+}
+
+// Numbers outside the range of an int64 are an error rather than wrapping.
 func ToIntImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	n := Car(args)
 	if !NumberP(n) {
@@ -364,9 +590,38 @@ func ToIntImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 		return
 	}
 
+	inRange := true
+	switch {
+	case BigIntegerP(n):
+		inRange = BigIntegerValue(n).IsInt64()
+	case FloatP(n):
+		f := float64(FloatValue(n))
+		inRange = f >= math.MinInt64 && f < math.MaxInt64
+	}
+	if !inRange {
+		err = ProcessError(fmt.Sprintf("integer: %s is out of the range of an integer", String(n)), env)
+		return
+	}
+
 	return IntegerWithValue(IntegerValue(n)), nil
 }
 
+// (big n) makes a big integer from an integer, or from a string of decimal digits.
+func ToBigIntegerImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	n := Car(args)
+	switch {
+	case ExactIntegerP(n):
+		return BigIntegerWithValue(new(big.Int).Set(BigIntegerValue(n))), nil
+	case StringP(n):
+		value, ok := new(big.Int).SetString(StringValue(n), 10)
+		if ok {
+			return BigIntegerWithValue(value), nil
+		}
+	}
+	err = ProcessError(fmt.Sprintf("big expected an integer or a string of digits, received %s", String(n)), env)
+	return
+}
+
 func ToFloatImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	n := Car(args)
 	if !NumberP(n) {
@@ -379,7 +634,10 @@ func ToFloatImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 
 func NumberToStringImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	valObj := First(args)
-	val := IntegerValue(valObj)
+	var val interface{} = IntegerValue(valObj)
+	if BigIntegerP(valObj) {
+		val = BigIntegerValue(valObj)
+	}
 	var base int64
 	if Length(args) == 2 {
 		baseObj := Second(args)
@@ -405,6 +663,110 @@ func NumberToStringImpl(args *Data, env *SymbolTableFrame) (result *Data, err er
 	return StringWithValue(fmt.Sprintf(format, val)), nil
 }
 
+func groupDigits(digits string, size int, separator string) string {
+	if separator == "" || len(digits) <= size {
+		return digits
+	}
+	groups := make([]string, 0, len(digits)/size+1)
+	first := len(digits) % size
+	if first > 0 {
+		groups = append(groups, digits[:first])
+	}
+	for i := first; i < len(digits); i += size {
+		groups = append(groups, digits[i:i+size])
+	}
+	return strings.Join(groups, separator)
+}
+
+// Formats a number according to an options alist:
+//   base     - 2, 8, 10 (default), or 16; floats only support base 10
+//   width    - the minimum width of the result, default 0
+//   pad      - a single character string to pad to the width with, default " "
+//   grouping - a separator placed between groups of digits: groups of three in
+//              base 10 and groups of four otherwise, default ""
+// Zero padding goes between the sign and the digits, any other padding before the sign.
+func NumberToFormattedStringImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	num := First(args)
+	if !NumberP(num) {
+		err = ProcessError(fmt.Sprintf("number->formatted-string requires a number, but received %s.", String(num)), env)
+		return
+	}
+
+	options := Second(args)
+	if !ListP(options) {
+		err = ProcessError(fmt.Sprintf("number->formatted-string requires an options alist, but received %s.", String(options)), env)
+		return
+	}
+
+	resolved, err := ParseOptions(options, map[string]*Data{
+		"base":     IntegerWithValue(10),
+		"width":    IntegerWithValue(0),
+		"pad":      StringWithValue(" "),
+		"grouping": StringWithValue(""),
+	}, false)
+	if err != nil {
+		err = ProcessError(fmt.Sprintf("number->formatted-string: %s", err), env)
+		return
+	}
+
+	base := resolved["base"]
+	width := resolved["width"]
+	pad := resolved["pad"]
+	separator := resolved["grouping"]
+
+	switch {
+	case !IntegerP(base) || (IntegerValue(base) != 2 && IntegerValue(base) != 8 && IntegerValue(base) != 10 && IntegerValue(base) != 16):
+		err = ProcessError(fmt.Sprintf("number->formatted-string base must be 2, 8, 10, or 16, but was %s.", String(base)), env)
+	case FloatP(num) && IntegerValue(base) != 10:
+		err = ProcessError("number->formatted-string can only format floats in base 10.", env)
+	case !IntegerP(width) || IntegerValue(width) < 0:
+		err = ProcessError(fmt.Sprintf("number->formatted-string width must be a non-negative integer, but was %s.", String(width)), env)
+	case !StringP(pad) || len([]rune(StringValue(pad))) != 1:
+		err = ProcessError(fmt.Sprintf("number->formatted-string pad must be a single character string, but was %s.", String(pad)), env)
+	case !StringP(separator):
+		err = ProcessError(fmt.Sprintf("number->formatted-string grouping must be a string, but was %s.", String(separator)), env)
+	}
+	if err != nil {
+		return
+	}
+
+	var digits, fraction, sign string
+	if ExactIntegerP(num) {
+		val := BigIntegerValue(num)
+		if val.Sign() < 0 {
+			sign = "-"
+		}
+		digits = strings.TrimPrefix(val.Text(int(IntegerValue(base))), "-")
+	} else {
+		val := FloatValue(num)
+		if val < 0 {
+			sign = "-"
+		}
+		digits = strings.TrimPrefix(strconv.FormatFloat(float64(val), 'f', -1, 32), "-")
+		if point := strings.Index(digits, "."); point >= 0 {
+			digits, fraction = digits[:point], digits[point:]
+		}
+	}
+
+	groupSize := 4
+	if IntegerValue(base) == 10 {
+		groupSize = 3
+	}
+	body := groupDigits(digits, groupSize, StringValue(separator)) + fraction
+
+	padding := int(IntegerValue(width)) - len(sign) - len([]rune(body))
+	if padding > 0 {
+		padString := strings.Repeat(StringValue(pad), padding)
+		if StringValue(pad) == "0" {
+			body = padString + body
+		} else {
+			sign = padString + sign
+		}
+	}
+
+	return StringWithValue(sign + body), nil
+}
+
 func StringToNumberImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	strObj := First(args)
 	str := StringValue(strObj)
@@ -437,26 +799,100 @@ func StringToNumberImpl(args *Data, env *SymbolTableFrame) (result *Data, err er
 	return IntegerWithValue(val), nil
 }
 
+func radixArg(name string, args *Data, env *SymbolTableFrame) (radix int64, err error) {
+	if Length(args) < 2 {
+		return 10, nil
+	}
+	r := Second(args)
+	if !IntegerP(r) || IntegerValue(r) < 2 || IntegerValue(r) > 36 {
+		err = ProcessError(fmt.Sprintf("%s requires a radix from 2 to 36, but received %s.", name, String(r)), env)
+		return
+	}
+	return IntegerValue(r), nil
+}
+
+// Characters are represented as single character strings. Returns #f if the
+// character isn't a digit in the radix (default 10); letters are case insensitive.
+func CharToDigitImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	ch := First(args)
+	if !StringP(ch) || len([]rune(StringValue(ch))) != 1 {
+		err = ProcessError(fmt.Sprintf("char->digit requires a single character string, but received %s.", String(ch)), env)
+		return
+	}
+	radix, err := radixArg("char->digit", args, env)
+	if err != nil {
+		return
+	}
+
+	c := []rune(strings.ToLower(StringValue(ch)))[0]
+	var digit int64
+	switch {
+	case c >= '0' && c <= '9':
+		digit = int64(c - '0')
+	case c >= 'a' && c <= 'z':
+		digit = int64(c-'a') + 10
+	default:
+		return LispFalse, nil
+	}
+	if digit >= radix {
+		return LispFalse, nil
+	}
+	return IntegerWithValue(digit), nil
+}
+
+func DigitToCharImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	d := First(args)
+	if !IntegerP(d) {
+		err = ProcessError(fmt.Sprintf("digit->char requires an integer digit, but received %s.", String(d)), env)
+		return
+	}
+	radix, err := radixArg("digit->char", args, env)
+	if err != nil {
+		return
+	}
+
+	digit := IntegerValue(d)
+	if digit < 0 || digit >= radix {
+		err = ProcessError(fmt.Sprintf("digit->char: %d is not a digit in base %d.", digit, radix), env)
+		return
+	}
+	return StringWithValue(strconv.FormatInt(digit, int(radix))), nil
+}
+
+// Compares two integers, either of which may be a big integer.
+func compareIntegers(a *Data, b *Data) int {
+	if IntegerP(a) && IntegerP(b) {
+		switch {
+		case IntegerValue(a) < IntegerValue(b):
+			return -1
+		case IntegerValue(a) > IntegerValue(b):
+			return 1
+		}
+		return 0
+	}
+	return BigIntegerValue(a).Cmp(BigIntegerValue(b))
+}
+
 func minInts(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	n := Car(args)
-	if !IntegerP(n) {
+	if !ExactIntegerP(n) {
 		err = ProcessError(fmt.Sprintf("min requires numbers, received %s", String(n)), env)
 		return
 	}
-	var acc int64 = IntegerValue(n)
+	acc := n
 
 	for c := Cdr(args); NotNilP(c); c = Cdr(c) {
 		n = Car(c)
-		if !IntegerP(n) {
+		if !ExactIntegerP(n) {
 			err = ProcessError(fmt.Sprintf("min requires numbers, received %s", String(n)), env)
 			return
 		}
-		if IntegerValue(n) < acc {
-			acc = IntegerValue(n)
+		if compareIntegers(n, acc) < 0 {
+			acc = n
 		}
 	}
 
-	return IntegerWithValue(acc), nil
+	return acc, nil
 }
 
 func minFloats(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -504,24 +940,24 @@ func MinImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 
 func maxInts(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	n := Car(args)
-	if !IntegerP(n) {
+	if !ExactIntegerP(n) {
 		err = ProcessError(fmt.Sprintf("max requires numbers, received %s", String(n)), env)
 		return
 	}
-	var acc int64 = IntegerValue(n)
+	acc := n
 
 	for c := Cdr(args); NotNilP(c); c = Cdr(c) {
 		n = Car(c)
-		if !IntegerP(n) {
+		if !ExactIntegerP(n) {
 			err = ProcessError(fmt.Sprintf("max requires numbers, received %s", String(n)), env)
 			return
 		}
-		if IntegerValue(n) > acc {
-			acc = IntegerValue(n)
+		if compareIntegers(n, acc) > 0 {
+			acc = n
 		}
 	}
 
-	return IntegerWithValue(acc), nil
+	return acc, nil
 }
 
 func maxFloats(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -579,6 +1015,35 @@ func FloorImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return FloatWithValue(float32(math.Floor(float64(FloatValue(val))))), nil
 }
 
+// Returns the floored quotient and the remainder, which has the sign of the
+// divisor, as two values.
+func FloorDivideImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	dividend, divisor, err := integerDivisionArgs("floor/", args, env)
+	if err != nil {
+		return
+	}
+
+	if bigIntegerDivisionP(dividend, divisor) {
+		d := BigIntegerValue(divisor)
+		q, r := new(big.Int).QuoRem(BigIntegerValue(dividend), d, new(big.Int))
+		if r.Sign() != 0 && (r.Sign() < 0) != (d.Sign() < 0) {
+			q.Sub(q, big.NewInt(1))
+			r.Add(r, d)
+		}
+		return ValuesWithArray([]*Data{BigIntegerWithValue(q), BigIntegerWithValue(r)}), nil
+	}
+
+	n := IntegerValue(dividend)
+	d := IntegerValue(divisor)
+	q := n / d
+	r := n % d
+	if r != 0 && (r < 0) != (d < 0) {
+		q--
+		r += d
+	}
+	return ValuesWithArray([]*Data{IntegerWithValue(q), IntegerWithValue(r)}), nil
+}
+
 func CeilingImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	val := Car(args)
 
@@ -590,17 +1055,43 @@ func CeilingImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return FloatWithValue(float32(math.Ceil(float64(FloatValue(val))))), nil
 }
 
+// Rounds to the nearest integer, and to the even one when halfway between two.
+func RoundImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	val := Car(args)
+
+	if !NumberP(val) {
+		err = ProcessError(fmt.Sprintf("round expected a number, received %s", String(Car(args))), env)
+		return
+	}
+
+	x := float64(FloatValue(val))
+	rounded := math.Floor(x)
+	if diff := x - rounded; diff > 0.5 || (diff == 0.5 && math.Mod(rounded, 2) != 0) {
+		rounded++
+	}
+	return FloatWithValue(float32(rounded)), nil
+}
+
 func AbsImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	val := Car(args)
 	if !NumberP(val) {
 		err = ProcessError(fmt.Sprintf("abs expected a number, received %s", String(Car(args))), env)
 		return
 	}
-	absval := math.Abs(float64(FloatValue(val)))
-	if IntegerP(val) {
-		result = IntegerWithValue(int64(absval))
-	} else {
-		result = FloatWithValue(float32(absval))
+	switch {
+	case BigIntegerP(val):
+		result = BigIntegerWithValue(new(big.Int).Abs(BigIntegerValue(val)))
+	case IntegerP(val):
+		n := IntegerValue(val)
+		if n == math.MinInt64 {
+			result = BigIntegerWithValue(new(big.Int).Neg(BigIntegerValue(val)))
+		} else if n < 0 {
+			result = IntegerWithValue(-n)
+		} else {
+			result = val
+		}
+	default:
+		result = FloatWithValue(float32(math.Abs(float64(FloatValue(val)))))
 	}
 	return
 }
@@ -634,20 +1125,20 @@ func NegativeImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 
 func EvenImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	val := Car(args)
-	if !IntegerP(val) {
+	if !ExactIntegerP(val) {
 		err = ProcessError(fmt.Sprintf("even? expected an integer, received %s", String(Car(args))), env)
 		return
 	}
-	return BooleanWithValue(IntegerValue(val)%2 == 0), nil
+	return BooleanWithValue(BigIntegerValue(val).Bit(0) == 0), nil
 }
 
 func OddImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	val := Car(args)
-	if !IntegerP(val) {
+	if !ExactIntegerP(val) {
 		err = ProcessError(fmt.Sprintf("odd? expected an integer, received %s", String(Car(args))), env)
 		return
 	}
-	return BooleanWithValue(IntegerValue(val)%2 != 0), nil
+	return BooleanWithValue(BigIntegerValue(val).Bit(0) != 0), nil
 }
 
 func SignImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -660,10 +1151,13 @@ func SignImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	if FloatP(val) {
 		return IntegerWithValue(sgn(float32(FloatValue(val)))), nil
 	} else {
-		return IntegerWithValue(intSgn(IntegerValue(val))), nil
+		return IntegerWithValue(int64(BigIntegerValue(val).Sign())), nil
 	}
 }
 
+// The largest integer pow will compute, in bits.
+const maxPowBits = 1 << 20
+
 func PowImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	areFloats, err := anyFloats(args, env)
 	if err != nil {
@@ -676,17 +1170,21 @@ func PowImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	if areFloats {
 		return FloatWithValue(float32(math.Pow(float64(FloatValue(base)), float64(FloatValue(exponent))))), nil
 	} else {
-		ret := int64(1)
-		b := IntegerValue(base)
-		e := IntegerValue(exponent)
-		for e > 0 {
-			if e&1 != 0 {
-				ret *= b
-			}
-			b *= b
-			e >>= 1
+		if BigIntegerP(exponent) && !BigIntegerValue(exponent).IsInt64() {
+			err = ProcessError(fmt.Sprintf("pow: the exponent %s is too large", String(exponent)), env)
+			return
+		}
+		// The result has at least (bits of base - 1) * exponent bits.
+		if baseBits := int64(BigIntegerValue(base).BitLen()); baseBits > 1 && IntegerValue(exponent) > maxPowBits/(baseBits-1) {
+			err = ProcessError(fmt.Sprintf("pow: %s to the power %s would be too large", String(base), String(exponent)), env)
+			return
+		}
+		// A negative exponent gives 1.
+		ret := new(big.Int).Exp(BigIntegerValue(base), BigIntegerValue(exponent), nil)
+		if !BigIntegerP(base) && !BigIntegerP(exponent) && ret.IsInt64() {
+			return IntegerWithValue(ret.Int64()), nil
 		}
-		return IntegerWithValue(ret), nil
+		return BigIntegerWithValue(ret), nil
 	}
 }
 
@@ -0,0 +1,82 @@
+// Copyright 2014 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file contains the memoization primitive functions.
+
+package golisp
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A memoized function remembers the result of applying its function to each
+// list of arguments it has seen. Arguments are keyed by their printed form,
+// so only functions whose arguments print distinctly when they differ (numbers,
+// strings, symbols, and lists and frames of them) memoize correctly; two
+// different objects that print the same share a cache entry.
+type MemoizedFunction struct {
+	Function *Data
+	Cache    map[string]*Data
+	Mutex    sync.Mutex
+}
+
+func RegisterMemoizePrimitives() {
+	MakePrimitiveFunction("memoize", "1", MemoizeImpl)
+	MakePrimitiveFunction("clear-memoize", "1", ClearMemoizeImpl)
+}
+
+func (self *MemoizedFunction) call(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	key := String(args)
+	self.Mutex.Lock()
+	result, found := self.Cache[key]
+	self.Mutex.Unlock()
+	if found {
+		return
+	}
+
+	result, err = ApplyWithoutEval(self.Function, args, env)
+	if err != nil {
+		return
+	}
+	self.Mutex.Lock()
+	self.Cache[key] = result
+	self.Mutex.Unlock()
+	return
+}
+
+func MemoizeImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	f := Car(args)
+	if !FunctionOrPrimitiveP(f) {
+		err = ProcessError(fmt.Sprintf("memoize requires a function, but received %s.", String(f)), env)
+		return
+	}
+
+	name := "memoized"
+	if FunctionP(f) {
+		name = fmt.Sprintf("memoized %s", FunctionValue(f).Name)
+	}
+	memoized := &MemoizedFunction{Function: f, Cache: make(map[string]*Data)}
+	p := &PrimitiveFunction{Name: name, Special: false, Body: memoized.call, Memoized: memoized}
+	p.parseNumArgs("*")
+	return PrimitiveWithNameAndFunc(name, p), nil
+}
+
+func ClearMemoizeImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	f := Car(args)
+	var memoized *MemoizedFunction
+	if PrimitiveP(f) {
+		memoized = PrimitiveValue(f).Memoized
+	}
+	if memoized == nil {
+		err = ProcessError(fmt.Sprintf("clear-memoize requires a memoized function, but received %s.", String(f)), env)
+		return
+	}
+
+	memoized.Mutex.Lock()
+	memoized.Cache = make(map[string]*Data)
+	memoized.Mutex.Unlock()
+	return f, nil
+}
@@ -7,13 +7,16 @@
 
 package golisp
 
-import ()
+import (
+	"fmt"
+)
 
 func RegisterMutatorPrimitives() {
 	MakeSpecialForm("set!", "2", SetVarImpl)
 	MakeSpecialForm("set-car!", "2", SetCarImpl)
 	MakeSpecialForm("set-cdr!", "2", SetCdrImpl)
 	MakeSpecialForm("set-nth!", "3", SetNthImpl)
+	MakePrimitiveFunction("list-set!", "3", ListSetImpl)
 }
 
 func SetVarImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -71,3 +74,32 @@ func SetNthImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 
 	return SetNth(l, int(IntegerValue(index)), value), nil
 }
+
+// Replaces the car of the kth (zero based) cell of a list. The list is
+// modified in place, so the change is visible through anything sharing it.
+func ListSetImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	l := First(args)
+	if !ListP(l) {
+		err = ProcessError(fmt.Sprintf("list-set! requires a list as it's first argument, but received %s.", String(l)), env)
+		return
+	}
+
+	index := Second(args)
+	if !IntegerP(index) {
+		err = ProcessError(fmt.Sprintf("list-set! requires an integer index, but received %s.", String(index)), env)
+		return
+	}
+
+	k := IntegerValue(index)
+	cell := l
+	for i := int64(0); i < k && PairP(cell) && NotNilP(cell); i++ {
+		cell = Cdr(cell)
+	}
+	if k < 0 || !PairP(cell) || NilP(cell) {
+		err = ProcessError(fmt.Sprintf("list-set! index %d is out of range for a list of length %d.", k, Length(l)), env)
+		return
+	}
+
+	ConsValue(cell).Car = Third(args)
+	return Third(args), nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2014 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file contains the ordered map primitive functions.
+
+package golisp
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// An OrderedMap is a hash table that remembers the order its keys were first
+// added in. Setting an existing key keeps its position; removing a key and
+// adding it again moves it to the end.
+type OrderedMap struct {
+	Entries hashBuckets
+	Order   []*Data
+	Mutex   sync.RWMutex
+}
+
+func RegisterOrderedMapPrimitives() {
+	MakePrimitiveFunction("make-ordered-map", "0", MakeOrderedMapImpl)
+	MakePrimitiveFunction("ordered-map?", "1", OrderedMapPImpl)
+	MakePrimitiveFunction("ordered-map-set!", "3", OrderedMapSetImpl)
+	MakePrimitiveFunction("ordered-map-ref", "2|3", OrderedMapRefImpl)
+	MakePrimitiveFunction("ordered-map-has-key?", "2", OrderedMapHasKeyImpl)
+	MakePrimitiveFunction("ordered-map-remove!", "2", OrderedMapRemoveImpl)
+	MakePrimitiveFunction("ordered-map-size", "1", OrderedMapSizeImpl)
+	MakePrimitiveFunction("ordered-map-keys", "1", OrderedMapKeysImpl)
+	MakePrimitiveFunction("ordered-map-values", "1", OrderedMapValuesImpl)
+	MakePrimitiveFunction("ordered-map->alist", "1", OrderedMapToAlistImpl)
+}
+
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{Entries: make(hashBuckets)}
+}
+
+func OrderedMapWithValue(m *OrderedMap) *Data {
+	return ObjectWithTypeAndValue("OrderedMap", unsafe.Pointer(m))
+}
+
+func OrderedMapP(d *Data) bool {
+	return ObjectP(d) && ObjectType(d) == "OrderedMap"
+}
+
+func OrderedMapValue(d *Data) *OrderedMap {
+	return (*OrderedMap)(ObjectValue(d))
+}
+
+func (self *OrderedMap) Get(key *Data) (value *Data, found bool) {
+	self.Mutex.RLock()
+	entry, found := self.Entries.get(key)
+	self.Mutex.RUnlock()
+	return entry.Value, found
+}
+
+// Order holds the keys as they were first added, which are the ones the
+// entries keep.
+func (self *OrderedMap) Set(key *Data, value *Data) {
+	self.Mutex.Lock()
+	if self.Entries.set(key, value) {
+		self.Order = append(self.Order, key)
+	}
+	self.Mutex.Unlock()
+}
+
+func (self *OrderedMap) Remove(key *Data) (found bool) {
+	self.Mutex.Lock()
+	removed, found := self.Entries.remove(key)
+	if found {
+		for i, existing := range self.Order {
+			if existing == removed.Key {
+				self.Order = append(self.Order[:i], self.Order[i+1:]...)
+				break
+			}
+		}
+	}
+	self.Mutex.Unlock()
+	return
+}
+
+func (self *OrderedMap) Size() int {
+	self.Mutex.RLock()
+	size := len(self.Order)
+	self.Mutex.RUnlock()
+	return size
+}
+
+// Returns the entries in insertion order.
+func (self *OrderedMap) entries() []hashTableEntry {
+	self.Mutex.RLock()
+	entries := make([]hashTableEntry, 0, len(self.Order))
+	for _, k := range self.Order {
+		entry, _ := self.Entries.get(k)
+		entries = append(entries, entry)
+	}
+	self.Mutex.RUnlock()
+	return entries
+}
+
+// Converts the map to a JSON object that keeps its key order, using convert
+// for the values.
+func (self *OrderedMap) toJson(convert func(*Data) interface{}) orderedJsonObject {
+	entries := self.entries()
+	obj := orderedJsonObject{Keys: make([]string, 0, len(entries)), Values: make([]interface{}, 0, len(entries))}
+	for _, entry := range entries {
+		key := String(entry.Key)
+		if StringP(entry.Key) || SymbolP(entry.Key) {
+			key = StringValue(entry.Key)
+		}
+		obj.Keys = append(obj.Keys, key)
+		obj.Values = append(obj.Values, convert(entry.Value))
+	}
+	return obj
+}
+
+func orderedMapArg(name string, args *Data, env *SymbolTableFrame) (m *OrderedMap, err error) {
+	d := Car(args)
+	if !OrderedMapP(d) {
+		err = ProcessError(fmt.Sprintf("%s requires an ordered map as it's first argument, but received %s.", name, String(d)), env)
+		return
+	}
+	return OrderedMapValue(d), nil
+}
+
+func MakeOrderedMapImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return OrderedMapWithValue(NewOrderedMap()), nil
+}
+
+func OrderedMapPImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return BooleanWithValue(OrderedMapP(Car(args))), nil
+}
+
+func OrderedMapSetImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	m, err := orderedMapArg("ordered-map-set!", args, env)
+	if err != nil {
+		return
+	}
+	m.Set(Second(args), Third(args))
+	return Third(args), nil
+}
+
+func OrderedMapRefImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	m, err := orderedMapArg("ordered-map-ref", args, env)
+	if err != nil {
+		return
+	}
+	value, found := m.Get(Second(args))
+	if !found {
+		return Third(args), nil
+	}
+	return value, nil
+}
+
+func OrderedMapHasKeyImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	m, err := orderedMapArg("ordered-map-has-key?", args, env)
+	if err != nil {
+		return
+	}
+	_, found := m.Get(Second(args))
+	return BooleanWithValue(found), nil
+}
+
+func OrderedMapRemoveImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	m, err := orderedMapArg("ordered-map-remove!", args, env)
+	if err != nil {
+		return
+	}
+	return BooleanWithValue(m.Remove(Second(args))), nil
+}
+
+func OrderedMapSizeImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	m, err := orderedMapArg("ordered-map-size", args, env)
+	if err != nil {
+		return
+	}
+	return IntegerWithValue(int64(m.Size())), nil
+}
+
+func OrderedMapKeysImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	m, err := orderedMapArg("ordered-map-keys", args, env)
+	if err != nil {
+		return
+	}
+	entries := m.entries()
+	keys := make([]*Data, 0, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	return ArrayToList(keys), nil
+}
+
+func OrderedMapValuesImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	m, err := orderedMapArg("ordered-map-values", args, env)
+	if err != nil {
+		return
+	}
+	entries := m.entries()
+	values := make([]*Data, 0, len(entries))
+	for _, entry := range entries {
+		values = append(values, entry.Value)
+	}
+	return ArrayToList(values), nil
+}
+
+func OrderedMapToAlistImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	m, err := orderedMapArg("ordered-map->alist", args, env)
+	if err != nil {
+		return
+	}
+	entries := m.entries()
+	for i := len(entries) - 1; i >= 0; i-- {
+		result = Acons(entries[i].Key, entries[i].Value, result)
+	}
+	return
+}
@@ -0,0 +1,133 @@
+// Copyright 2014 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file contains the regular expression primitive functions.
+
+package golisp
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Compiled patterns are cached by their source so hot patterns are only compiled once.
+var compiledRegexps = make(map[string]*regexp.Regexp)
+var compiledRegexpsMutex sync.Mutex
+
+func RegisterRegexPrimitives() {
+	MakePrimitiveFunction("regex-match?", "2", RegexMatchImpl)
+	MakePrimitiveFunction("regex-find", "2", RegexFindImpl)
+	MakePrimitiveFunction("regex-find-groups", "2", RegexFindGroupsImpl)
+	MakePrimitiveFunction("regex-find-all", "2", RegexFindAllImpl)
+	MakePrimitiveFunction("regex-replace", "3", RegexReplaceImpl)
+}
+
+func compileRegexp(pattern string) (re *regexp.Regexp, err error) {
+	compiledRegexpsMutex.Lock()
+	defer compiledRegexpsMutex.Unlock()
+	re, found := compiledRegexps[pattern]
+	if found {
+		return
+	}
+	re, err = regexp.Compile(pattern)
+	if err != nil {
+		return
+	}
+	compiledRegexps[pattern] = re
+	return
+}
+
+// Checks that the first argument is a pattern and the last the string to
+// search, returning the compiled pattern.
+func regexArgs(name string, args *Data, env *SymbolTableFrame) (re *regexp.Regexp, input string, err error) {
+	pattern := First(args)
+	if !StringP(pattern) {
+		err = ProcessError(fmt.Sprintf("%s requires a string pattern, but received %s.", name, String(pattern)), env)
+		return
+	}
+	s := Nth(args, Length(args))
+	if !StringP(s) {
+		err = ProcessError(fmt.Sprintf("%s requires a string to search, but received %s.", name, String(s)), env)
+		return
+	}
+	re, err = compileRegexp(StringValue(pattern))
+	if err != nil {
+		err = ProcessError(fmt.Sprintf("%s could not compile the pattern %s: %s", name, String(pattern), err), env)
+		return
+	}
+	return re, StringValue(s), nil
+}
+
+func RegexMatchImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	re, input, err := regexArgs("regex-match?", args, env)
+	if err != nil {
+		return
+	}
+	return BooleanWithValue(re.MatchString(input)), nil
+}
+
+// Returns the first match, or nil if there isn't one.
+func RegexFindImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	re, input, err := regexArgs("regex-find", args, env)
+	if err != nil {
+		return
+	}
+	loc := re.FindStringIndex(input)
+	if loc == nil {
+		return
+	}
+	return StringWithValue(input[loc[0]:loc[1]]), nil
+}
+
+// Returns a list of the first match followed by its capture groups, with nil
+// for groups that didn't participate, or nil if there is no match.
+func RegexFindGroupsImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	re, input, err := regexArgs("regex-find-groups", args, env)
+	if err != nil {
+		return
+	}
+	loc := re.FindStringSubmatchIndex(input)
+	if loc == nil {
+		return
+	}
+	groups := make([]*Data, 0, len(loc)/2)
+	for i := 0; i < len(loc); i += 2 {
+		if loc[i] < 0 {
+			groups = append(groups, nil)
+		} else {
+			groups = append(groups, StringWithValue(input[loc[i]:loc[i+1]]))
+		}
+	}
+	return ArrayToList(groups), nil
+}
+
+func RegexFindAllImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	re, input, err := regexArgs("regex-find-all", args, env)
+	if err != nil {
+		return
+	}
+	matches := re.FindAllString(input, -1)
+	items := make([]*Data, 0, len(matches))
+	for _, m := range matches {
+		items = append(items, StringWithValue(m))
+	}
+	return ArrayToList(items), nil
+}
+
+// (regex-replace pattern replacement input) replaces every match; the
+// replacement can refer to groups as $1 or ${name}.
+func RegexReplaceImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	re, input, err := regexArgs("regex-replace", args, env)
+	if err != nil {
+		return
+	}
+	replacement := Second(args)
+	if !StringP(replacement) {
+		err = ProcessError(fmt.Sprintf("regex-replace requires a string replacement, but received %s.", String(replacement)), env)
+		return
+	}
+	return StringWithValue(re.ReplaceAllString(input, StringValue(replacement))), nil
+}
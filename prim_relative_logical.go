@@ -17,7 +17,7 @@ func RegisterRelativePrimitives() {
 	MakePrimitiveFunction("==", "2", EqualToImpl)
 	MakePrimitiveFunction("eqv?", "2", EqualToImpl)
 	MakePrimitiveFunction("eq?", "2", EqualToImpl)
-	MakePrimitiveFunction("equal?", "2", EqualToImpl)
+	MakePrimitiveFunction("equal?", "2", DeepEqualImpl)
 	MakePrimitiveFunction("!=", "2", NotEqualImpl)
 	MakePrimitiveFunction("neq?", "2", NotEqualImpl)
 	MakePrimitiveFunction("<=", "2", LessThanOrEqualToImpl)
@@ -28,6 +28,15 @@ func RegisterRelativePrimitives() {
 	MakeSpecialForm("or", "*", BooleanOrImpl)
 }
 
+// Compares integers exactly when either is a big integer, which the float
+// comparison used otherwise can't represent.
+func compareBigIntegers(arg1 *Data, arg2 *Data) (exact bool, cmp int) {
+	if (BigIntegerP(arg1) || BigIntegerP(arg2)) && !FloatP(arg1) && !FloatP(arg2) {
+		return true, BigIntegerValue(arg1).Cmp(BigIntegerValue(arg2))
+	}
+	return false, 0
+}
+
 func LessThanImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	arg1 := Car(args)
 	if !NumberP(arg1) {
@@ -41,6 +50,10 @@ func LessThanImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 		return
 	}
 
+	if exact, cmp := compareBigIntegers(arg1, arg2); exact {
+		return BooleanWithValue(cmp < 0), nil
+	}
+
 	val := FloatValue(arg1) < FloatValue(arg2)
 	return BooleanWithValue(val), nil
 }
@@ -58,6 +71,10 @@ func GreaterThanImpl(args *Data, env *SymbolTableFrame) (result *Data, err error
 		return
 	}
 
+	if exact, cmp := compareBigIntegers(arg1, arg2); exact {
+		return BooleanWithValue(cmp > 0), nil
+	}
+
 	val := FloatValue(arg1) > FloatValue(arg2)
 	return BooleanWithValue(val), nil
 }
@@ -68,6 +85,10 @@ func EqualToImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return BooleanWithValue(IsEqual(arg1, arg2)), nil
 }
 
+func DeepEqualImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return BooleanWithValue(IsEqualDeep(Car(args), Cadr(args))), nil
+}
+
 func NotEqualImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	arg1 := Car(args)
 	arg2 := Cadr(args)
@@ -87,6 +108,10 @@ func LessThanOrEqualToImpl(args *Data, env *SymbolTableFrame) (result *Data, err
 		return
 	}
 
+	if exact, cmp := compareBigIntegers(arg1, arg2); exact {
+		return BooleanWithValue(cmp <= 0), nil
+	}
+
 	val := FloatValue(arg1) <= FloatValue(arg2)
 	return BooleanWithValue(val), nil
 }
@@ -104,6 +129,10 @@ func GreaterThanOrEqualToImpl(args *Data, env *SymbolTableFrame) (result *Data,
 		return
 	}
 
+	if exact, cmp := compareBigIntegers(arg1, arg2); exact {
+		return BooleanWithValue(cmp >= 0), nil
+	}
+
 	val := FloatValue(arg1) >= FloatValue(arg2)
 	return BooleanWithValue(val), nil
 }
@@ -113,7 +142,15 @@ func BooleanNotImpl(args *Data, env *SymbolTableFrame) (result *Data, err error)
 }
 
 func BooleanAndImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, _, err = evalAnd(args, env, false)
+	return
+}
+
+func evalAnd(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
 	for c := args; NotNilP(c); c = Cdr(c) {
+		if NilP(Cdr(c)) {
+			return evalMaybeTail(Car(c), env, tail)
+		}
 		result, err = Eval(Car(c), env)
 		if err != nil || !BooleanValue(result) {
 			return
@@ -123,7 +160,15 @@ func BooleanAndImpl(args *Data, env *SymbolTableFrame) (result *Data, err error)
 }
 
 func BooleanOrImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, _, err = evalOr(args, env, false)
+	return
+}
+
+func evalOr(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
 	for c := args; NotNilP(c); c = Cdr(c) {
+		if NilP(Cdr(c)) {
+			return evalMaybeTail(Car(c), env, tail)
+		}
 		result, err = Eval(Car(c), env)
 		if err != nil || BooleanValue(result) {
 			return
@@ -48,4 +48,12 @@ func InitBuiltins() {
 	RegisterEnvironmentPrimitives()
 	RegisterIOPrimitives()
 	RegisterChannelPrimitives()
+	RegisterHashTablePrimitives()
+	RegisterOrderedMapPrimitives()
+	RegisterGenericPrimitives()
+	RegisterStreamPrimitives()
+	RegisterValuesPrimitives()
+	RegisterAdvicePrimitives()
+	RegisterRegexPrimitives()
+	RegisterMemoizePrimitives()
 }
@@ -15,18 +15,25 @@ func RegisterSpecialFormPrimitives() {
 	MakeSpecialForm("cond", "*", CondImpl)
 	MakeSpecialForm("case", ">=1", CaseImpl)
 	MakeSpecialForm("if", "2|3", IfImpl)
-	MakeSpecialForm("when", ">=2", WhenImpl)
-	MakeSpecialForm("unless", ">=2", UnlessImpl)
+	MakeSpecialForm("when", ">=1", WhenImpl)
+	MakeSpecialForm("unless", ">=1", UnlessImpl)
+	MakeSpecialForm("if-let", "2|3", IfLetImpl)
+	MakeSpecialForm("when-let", ">=2", WhenLetImpl)
 	MakeSpecialForm("lambda", ">=1", LambdaImpl)
 	MakeSpecialForm("named-lambda", ">=1", NamedLambdaImpl)
 	MakeSpecialForm("define", ">=1", DefineImpl)
 	MakeSpecialForm("defmacro", ">=1", DefmacroImpl)
+	MakeSpecialForm("define-enum", "2", DefineEnumImpl)
 	MakeSpecialForm("let", ">=1", LetImpl)
 	MakeSpecialForm("let*", ">=1", LetStarImpl)
 	MakeSpecialForm("letrec", ">=1", LetRecImpl)
 	MakeSpecialForm("begin", "*", BeginImpl)
 	MakeSpecialForm("do", ">=2", DoImpl)
-	MakePrimitiveFunction("apply", ">=1", ApplyImpl)
+	MakeSpecialForm("while", ">=1", WhileImpl)
+	MakeSpecialForm("until", ">=1", UntilImpl)
+	MakeSpecialForm("for", ">=2", ForImpl)
+	MakeSpecialForm("for*", ">=2", ForStarImpl)
+	MakePrimitiveFunction("apply", ">=2", ApplyImpl)
 	MakeSpecialForm("->", ">=1", ChainImpl)
 	MakeSpecialForm("=>", ">=1", TapImpl)
 	MakeSpecialForm("definition-of", "1", DefinitionOfImpl)
@@ -43,6 +50,11 @@ func evaluateBody(sexprs *Data, env *SymbolTableFrame) (result *Data, err error)
 }
 
 func CondImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, _, err = evalCond(args, env, false)
+	return
+}
+
+func evalCond(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
 	var condition *Data
 	for c := args; NotNilP(c); c = Cdr(c) {
 		clause := Car(c)
@@ -51,21 +63,73 @@ func CondImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 			return
 		}
 		if IsEqual(Car(clause), Intern("else")) {
-			return evaluateBody(Cdr(clause), env)
+			return evalBodyMaybeTail(Cdr(clause), env, tail)
 		} else {
 			condition, err = Eval(Car(clause), env)
 			if err != nil {
 				return
 			}
 			if BooleanValue(condition) {
-				return evaluateBody(Cdr(clause), env)
+				if condArrowClauseP(clause) {
+					return applyCondArrow(clause, condition, env, tail)
+				}
+				return evalBodyMaybeTail(Cdr(clause), env, tail)
 			}
 		}
 	}
 	return
 }
 
+// The last form of each clause body is in tail position. The call an =>
+// clause makes is too, but it isn't a subform.
+func condTailPositions(args *Data) (positions []*Data) {
+	for c := args; NotNilP(c); c = Cdr(c) {
+		if !condArrowClauseP(Car(c)) {
+			positions = append(positions, lastForm(Cdar(c)))
+		}
+	}
+	return
+}
+
+// A clause of the form (test => f) passes the value of its test to f.
+func condArrowClauseP(clause *Data) bool {
+	return Length(clause) == 3 && IsEqual(Second(clause), Intern("=>"))
+}
+
+func applyCondArrow(clause *Data, value *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
+	f, err := Eval(Third(clause), env)
+	if err != nil {
+		return
+	}
+	if !FunctionOrPrimitiveP(f) {
+		err = ProcessError(fmt.Sprintf("Cond expected a function after =>, but received %s", String(f)), env)
+		return
+	}
+	return applyMaybeTail(f, InternalMakeList(value), env, tail)
+}
+
+// Splits a trailing (fallthrough) marker off a case clause body.
+func caseClauseBody(clause *Data) (body *Data, fallsThrough bool) {
+	body = Cdr(clause)
+	forms := ToArray(body)
+	if len(forms) == 0 {
+		return
+	}
+	last := forms[len(forms)-1]
+	if PairP(last) && Length(last) == 1 && IsEqual(Car(last), Intern("fallthrough")) {
+		return ArrayToList(forms[:len(forms)-1]), true
+	}
+	return
+}
+
+// A clause whose body ends with (fallthrough) continues into the body of the
+// clause immediately after it, without checking that clause's values.
 func CaseImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, _, err = evalCase(args, env, false)
+	return
+}
+
+func evalCase(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
 	var keyValue *Data
 
 	keyValue, err = Eval(Car(args), env)
@@ -73,78 +137,154 @@ func CaseImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 		return
 	}
 
+	falling := false
 	for clauseCell := Cdr(args); NotNilP(clauseCell); clauseCell = Cdr(clauseCell) {
 		clause := Car(clauseCell)
 		if !PairP(clause) {
 			err = ProcessError("Case expectes a sequence of clauses that are lists", env)
 			return
 		}
+
+		matched := falling
 		if IsEqual(Car(clause), Intern("else")) {
-			return evaluateBody(Cdr(clause), env)
+			matched = true
 		} else if ListP(Car(clause)) {
-			for v := Car(clause); NotNilP(v); v = Cdr(v) {
-				if IsEqual(Car(v), keyValue) {
-					return evaluateBody(Cdr(clause), env)
-				}
+			for v := Car(clause); !matched && NotNilP(v); v = Cdr(v) {
+				matched = IsEqual(Car(v), keyValue)
 			}
 		} else {
 			err = ProcessError("Case the condition part of clauses to be lists of 'else", env)
 			return
 		}
+
+		if matched {
+			body, fallsThrough := caseClauseBody(clause)
+			if !fallsThrough {
+				return evalBodyMaybeTail(body, env, tail)
+			}
+			result, err = evaluateBody(body, env)
+			if err != nil {
+				return
+			}
+			falling = true
+		}
 	}
 
 	return
 }
 
+// The last form of each clause body that doesn't fall through is in tail
+// position.
+func caseTailPositions(args *Data) (positions []*Data) {
+	for c := Cdr(args); NotNilP(c); c = Cdr(c) {
+		if body, fallsThrough := caseClauseBody(Car(c)); !fallsThrough {
+			positions = append(positions, lastForm(body))
+		}
+	}
+	return
+}
+
 func IfImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, _, err = evalIf(args, env, false)
+	return
+}
+
+func evalIf(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
 	c, err := Eval(Car(args), env)
 	if err != nil {
 		return
 	}
 
 	if BooleanValue(c) {
-		return Eval(Second(args), env)
+		return evalMaybeTail(Second(args), env, tail)
 	} else {
-		return Eval(Third(args), env)
+		return evalMaybeTail(Third(args), env, tail)
 	}
 }
 
+func ifTailPositions(args *Data) []*Data {
+	return []*Data{Second(args), Third(args)}
+}
+
 func WhenImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, _, err = evalWhen(args, env, false)
+	return
+}
+
+func evalWhen(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
 	c, err := Eval(Car(args), env)
 	if err != nil {
 		return
 	}
 
 	if BooleanValue(c) {
-		for cell := Cdr(args); NotNilP(cell); cell = Cdr(cell) {
-			sexpr := Car(cell)
-			result, err = Eval(sexpr, env)
-			if err != nil {
-				return
-			}
-		}
+		return evalBodyMaybeTail(Cdr(args), env, tail)
 	}
 	return
 }
 
 func UnlessImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, _, err = evalUnless(args, env, false)
+	return
+}
+
+func evalUnless(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
 	c, err := Eval(Car(args), env)
 	if err != nil {
 		return
 	}
 
 	if !BooleanValue(c) {
-		for cell := Cdr(args); NotNilP(cell); cell = Cdr(cell) {
-			sexpr := Car(cell)
-			result, err = Eval(sexpr, env)
-			if err != nil {
-				return
-			}
-		}
+		return evalBodyMaybeTail(Cdr(args), env, tail)
 	}
 	return
 }
 
+func whenTailPositions(args *Data) []*Data {
+	return []*Data{lastForm(Cdr(args))}
+}
+
+// Evaluates the expression of a (name expr) binding, returning a new
+// environment with it bound to name if it is true.
+func bindIfTrue(formName string, binding *Data, env *SymbolTableFrame) (localEnv *SymbolTableFrame, err error) {
+	if !PairP(binding) || Length(binding) != 2 || !SymbolP(Car(binding)) {
+		err = ProcessError(fmt.Sprintf("%s requires a (name expression) binding as it's first argument, but received %s.", formName, String(binding)), env)
+		return
+	}
+
+	value, err := Eval(Cadr(binding), env)
+	if err != nil || !BooleanValue(value) {
+		return
+	}
+
+	localEnv = NewSymbolTableFrameBelow(env, formName)
+	localEnv.Previous = env
+	_, err = localEnv.BindLocallyTo(Car(binding), value)
+	return
+}
+
+// The name is only bound while evaluating the then branch.
+func IfLetImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	localEnv, err := bindIfTrue("if-let", Car(args), env)
+	if err != nil {
+		return
+	}
+
+	if localEnv != nil {
+		return Eval(Second(args), localEnv)
+	} else {
+		return Eval(Third(args), env)
+	}
+}
+
+func WhenLetImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	localEnv, err := bindIfTrue("when-let", Car(args), env)
+	if err != nil || localEnv == nil {
+		return
+	}
+	return evaluateBody(Cdr(args), localEnv)
+}
+
 func LambdaImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	if !PairP(Car(args)) {
 		err = ProcessError("A lambda requires a parameter list", env)
@@ -201,6 +341,107 @@ func DefineImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return value, err
 }
 
+// (define-enum name (member...)) binds name:member to successive integers
+// starting at 0. A member may be given as (member value) to set its value,
+// with numbering continuing from there. It also defines name->string and
+// string->name to convert between values and member names.
+func DefineEnumImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	name := First(args)
+	if !SymbolP(name) {
+		err = ProcessError(fmt.Sprintf("define-enum requires a symbol name, but received %s.", String(name)), env)
+		return
+	}
+	enumName := StringValue(name)
+
+	members := Second(args)
+	if !PairP(members) || NilP(members) {
+		err = ProcessError(fmt.Sprintf("define-enum requires a list of members, but received %s.", String(members)), env)
+		return
+	}
+
+	// All members are checked before any are bound, so a bad member leaves
+	// nothing defined.
+	names := make(map[int64]string, Length(members))
+	values := make(map[string]int64, Length(members))
+	memberNames := make([]string, 0, Length(members))
+	var next int64
+	var valueObj *Data
+	for c := members; NotNilP(c); c = Cdr(c) {
+		member := Car(c)
+		if PairP(member) && Length(member) == 2 {
+			valueObj, err = Eval(Second(member), env)
+			if err != nil {
+				return
+			}
+			if !IntegerP(valueObj) {
+				err = ProcessError(fmt.Sprintf("define-enum member values must be integers, but %s was %s.", String(First(member)), String(valueObj)), env)
+				return
+			}
+			next = IntegerValue(valueObj)
+			member = First(member)
+		}
+		if !SymbolP(member) {
+			err = ProcessError(fmt.Sprintf("define-enum members must be symbols or (symbol value), but received %s.", String(Car(c))), env)
+			return
+		}
+		memberName := StringValue(member)
+		if _, found := values[memberName]; found {
+			err = ProcessError(fmt.Sprintf("define-enum %s has more than one member named %s.", enumName, memberName), env)
+			return
+		}
+
+		values[memberName] = next
+		if _, found := names[next]; !found {
+			names[next] = memberName
+		}
+		memberNames = append(memberNames, memberName)
+		next++
+	}
+
+	for _, memberName := range memberNames {
+		_, err = env.BindLocallyTo(Intern(fmt.Sprintf("%s:%s", enumName, memberName)), IntegerWithValue(values[memberName]))
+		if err != nil {
+			return
+		}
+	}
+
+	toStringName := fmt.Sprintf("%s->string", enumName)
+	toString := &PrimitiveFunction{Name: toStringName, Body: func(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+		value := Car(args)
+		if IntegerP(value) {
+			if memberName, found := names[IntegerValue(value)]; found {
+				return StringWithValue(memberName), nil
+			}
+		}
+		err = ProcessError(fmt.Sprintf("%s: %s is not a value of %s.", toStringName, String(value), enumName), env)
+		return
+	}}
+	toString.parseNumArgs("1")
+	_, err = env.BindLocallyTo(Intern(toStringName), PrimitiveWithNameAndFunc(toStringName, toString))
+	if err != nil {
+		return
+	}
+
+	fromStringName := fmt.Sprintf("string->%s", enumName)
+	fromString := &PrimitiveFunction{Name: fromStringName, Body: func(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+		memberName := Car(args)
+		if StringP(memberName) || SymbolP(memberName) {
+			if value, found := values[StringValue(memberName)]; found {
+				return IntegerWithValue(value), nil
+			}
+		}
+		err = ProcessError(fmt.Sprintf("%s: %s is not a member of %s.", fromStringName, String(memberName), enumName), env)
+		return
+	}}
+	fromString.parseNumArgs("1")
+	_, err = env.BindLocallyTo(Intern(fromStringName), PrimitiveWithNameAndFunc(fromStringName, fromString))
+	if err != nil {
+		return
+	}
+
+	return name, nil
+}
+
 func DefmacroImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	var value *Data
 	thing := Car(args)
@@ -261,13 +502,18 @@ func bindLetLocals(bindingForms *Data, rec bool, localEnv *SymbolTableFrame, eva
 	return
 }
 
-func LetCommon(args *Data, env *SymbolTableFrame, star bool, rec bool) (result *Data, err error) {
+// Creates the environment a let evaluates its body in.
+func letEnvironment(args *Data, env *SymbolTableFrame, star bool, rec bool) (localEnv *SymbolTableFrame, err error) {
 	if !PairP(Car(args)) {
 		err = ProcessError("Let requires a list of bindings as it's first argument", env)
 		return
 	}
 
-	localEnv := NewSymbolTableFrameBelow(env, "let")
+	if star {
+		return letStarEnvironment(Car(args), env)
+	}
+
+	localEnv = NewSymbolTableFrameBelow(env, "let")
 	localEnv.Previous = env
 	var evalEnv *SymbolTableFrame
 	if star || rec {
@@ -276,22 +522,42 @@ func LetCommon(args *Data, env *SymbolTableFrame, star bool, rec bool) (result *
 		evalEnv = env
 	}
 	err = bindLetLocals(Car(args), rec, localEnv, evalEnv)
-	if err != nil {
-		return
-	}
+	return
+}
 
-	for cell := Cdr(args); NotNilP(cell); cell = Cdr(cell) {
-		sexpr := Car(cell)
-		result, err = Eval(sexpr, localEnv)
+// Each binding of a let* gets its own frame below the one before it, so its
+// value is evaluated with the earlier bindings in scope, and anything that
+// captures a binding keeps it even if a later binding reuses the name.
+func letStarEnvironment(bindingForms *Data, env *SymbolTableFrame) (localEnv *SymbolTableFrame, err error) {
+	localEnv = NewSymbolTableFrameBelow(env, "let*")
+	localEnv.Previous = env
+	for cell := bindingForms; NotNilP(cell); cell = Cdr(cell) {
+		if cell != bindingForms {
+			localEnv = NewSymbolTableFrameBelow(localEnv, "let*")
+			localEnv.Previous = env
+		}
+		err = bindLetLocals(InternalMakeList(Car(cell)), false, localEnv, localEnv.Parent)
 		if err != nil {
 			return
 		}
 	}
+	return
+}
 
+func LetCommon(args *Data, env *SymbolTableFrame, star bool, rec bool) (result *Data, err error) {
+	result, _, err = evalLetCommon(args, env, star, rec, false)
 	return
 }
 
-func namedLetImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+func evalLetCommon(args *Data, env *SymbolTableFrame, star bool, rec bool, tail bool) (result *Data, next *tailCall, err error) {
+	localEnv, err := letEnvironment(args, env, star, rec)
+	if err != nil {
+		return
+	}
+	return evalBodyMaybeTail(Cdr(args), localEnv, tail)
+}
+
+func evalNamedLet(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
 	name := Car(args)
 	if !SymbolP(name) {
 		err = ProcessError("A named let requires a symbol name as its first argument", env)
@@ -328,14 +594,33 @@ func namedLetImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	if err != nil {
 		return
 	}
-	return FunctionValue(namedLetProc).Apply(initialsList, env)
+	if !tail {
+		result, err = FunctionValue(namedLetProc).Apply(initialsList, env)
+		return
+	}
+
+	values := make([]*Data, 0, len(initials))
+	var value *Data
+	for _, initial := range initials {
+		value, err = Eval(initial, env)
+		if err != nil {
+			return
+		}
+		values = append(values, value)
+	}
+	return applyMaybeTail(namedLetProc, ArrayToList(values), env, true)
 }
 
 func LetImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, _, err = evalLet(args, env, false)
+	return
+}
+
+func evalLet(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
 	if SymbolP(Car(args)) {
-		return namedLetImpl(args, env)
+		return evalNamedLet(args, env, tail)
 	} else {
-		return LetCommon(args, env, false, false)
+		return evalLetCommon(args, env, false, false, tail)
 	}
 }
 
@@ -343,21 +628,40 @@ func LetStarImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return LetCommon(args, env, true, false)
 }
 
+func evalLetStar(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
+	return evalLetCommon(args, env, true, false, tail)
+}
+
 func LetRecImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return LetCommon(args, env, false, true)
 }
 
-func BeginImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
-	for cell := args; NotNilP(cell); cell = Cdr(cell) {
-		sexpr := Car(cell)
-		result, err = Eval(sexpr, env)
-		if err != nil {
-			return
-		}
+func evalLetRec(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
+	return evalLetCommon(args, env, false, true, tail)
+}
+
+// The last form of the body is in tail position, including that of a named
+// let, which is the body of the function it calls.
+func letTailPositions(args *Data) []*Data {
+	if SymbolP(Car(args)) {
+		return []*Data{lastForm(Cddr(args))}
 	}
+	return []*Data{lastForm(Cdr(args))}
+}
+
+func BeginImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, _, err = evalBegin(args, env, false)
 	return
 }
 
+func evalBegin(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
+	return evalBodyMaybeTail(args, env, tail)
+}
+
+func bodyTailPositions(args *Data) []*Data {
+	return []*Data{lastForm(args)}
+}
+
 func rebindDoLocals(bindingForms *Data, env *SymbolTableFrame) (err error) {
 	var names []*Data
 	var values []*Data
@@ -389,6 +693,11 @@ func rebindDoLocals(bindingForms *Data, env *SymbolTableFrame) (err error) {
 }
 
 func DoImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, _, err = evalDo(args, env, false)
+	return
+}
+
+func evalDo(args *Data, env *SymbolTableFrame, tail bool) (result *Data, next *tailCall, err error) {
 	bindings := Car(args)
 	if !PairP(bindings) {
 		err = ProcessError("Do requires a list of bindings as it's first argument", env)
@@ -419,14 +728,7 @@ func DoImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 		}
 
 		if BooleanValue(shouldExit) {
-			for cell := Cdr(testClause); NotNilP(cell); cell = Cdr(cell) {
-				sexpr := Car(cell)
-				result, err = Eval(sexpr, localEnv)
-				if err != nil {
-					return
-				}
-			}
-			return
+			return evalBodyMaybeTail(Cdr(testClause), localEnv, tail)
 		}
 
 		for cell := body; NotNilP(cell); cell = Cdr(cell) {
@@ -437,13 +739,177 @@ func DoImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 			}
 		}
 
-		if rebindDoLocals(bindings, localEnv) != nil {
+		err = rebindDoLocals(bindings, localEnv)
+		if err != nil {
 			return
 		}
 	}
 	return
 }
 
+// The last of the result forms is in tail position.
+func doTailPositions(args *Data) []*Data {
+	return []*Data{lastForm(Cdr(Second(args)))}
+}
+
+func loopCommon(args *Data, env *SymbolTableFrame, runWhile bool) (result *Data, err error) {
+	test := Car(args)
+	body := Cdr(args)
+
+	var testResult *Data
+	for true {
+		testResult, err = Eval(test, env)
+		if err != nil {
+			return
+		}
+		if BooleanValue(testResult) != runWhile {
+			return nil, nil
+		}
+		_, err = evaluateBody(body, env)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+func WhileImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return loopCommon(args, env, true)
+}
+
+func UntilImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return loopCommon(args, env, false)
+}
+
+// for and for* collect the value of their body for each binding of their
+// clause variables: (for ((var list-expr) ...) [(when guard)] body...).
+// for walks the lists in parallel, stopping at the end of the shortest, while
+// for* walks their cross product with the first clause outermost. A leading
+// (when guard) form skips the bindings for which guard is false.
+
+func forClauses(name string, args *Data, env *SymbolTableFrame) (names []*Data, lists [][]*Data, guard *Data, body *Data, err error) {
+	clauses := Car(args)
+	if !PairP(clauses) || NilP(clauses) {
+		err = ProcessError(fmt.Sprintf("%s requires a list of clauses as it's first argument.", name), env)
+		return
+	}
+
+	for cell := clauses; NotNilP(cell); cell = Cdr(cell) {
+		clause := Car(cell)
+		if !PairP(clause) || Length(clause) != 2 || !SymbolP(Car(clause)) {
+			err = ProcessError(fmt.Sprintf("%s clauses must be of the form (symbol list), but received %s.", name, String(clause)), env)
+			return
+		}
+		var l *Data
+		l, err = Eval(Cadr(clause), env)
+		if err != nil {
+			return
+		}
+		if !ListP(l) {
+			err = ProcessError(fmt.Sprintf("%s requires %s to be bound to a list, but it was %s.", name, String(Car(clause)), String(l)), env)
+			return
+		}
+		names = append(names, Car(clause))
+		lists = append(lists, ToArray(l))
+	}
+
+	body = Cdr(args)
+	first := Car(body)
+	if PairP(first) && SymbolP(Car(first)) && StringValue(Car(first)) == "when" && Length(first) == 2 {
+		guard = Cadr(first)
+		body = Cdr(body)
+	}
+	if NilP(body) {
+		err = ProcessError(fmt.Sprintf("%s requires a body.", name), env)
+	}
+	return
+}
+
+func forCollect(names []*Data, values []*Data, guard *Data, body *Data, env *SymbolTableFrame) (include bool, result *Data, err error) {
+	localEnv := NewSymbolTableFrameBelow(env, "for")
+	localEnv.Previous = env
+	for i, name := range names {
+		_, err = localEnv.BindLocallyTo(name, values[i])
+		if err != nil {
+			return
+		}
+	}
+
+	if guard != nil {
+		var guardValue *Data
+		guardValue, err = Eval(guard, localEnv)
+		if err != nil || !BooleanValue(guardValue) {
+			return
+		}
+	}
+
+	result, err = evaluateBody(body, localEnv)
+	return err == nil, result, err
+}
+
+func ForImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	names, lists, guard, body, err := forClauses("for", args, env)
+	if err != nil {
+		return
+	}
+
+	count := len(lists[0])
+	for _, l := range lists {
+		if len(l) < count {
+			count = len(l)
+		}
+	}
+
+	collected := make([]*Data, 0, count)
+	values := make([]*Data, len(lists))
+	for i := 0; i < count; i++ {
+		for j, l := range lists {
+			values[j] = l[i]
+		}
+		include, value, err := forCollect(names, values, guard, body, env)
+		if err != nil {
+			return nil, err
+		}
+		if include {
+			collected = append(collected, value)
+		}
+	}
+	return ArrayToList(collected), nil
+}
+
+func ForStarImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	names, lists, guard, body, err := forClauses("for*", args, env)
+	if err != nil {
+		return
+	}
+
+	collected := make([]*Data, 0)
+	values := make([]*Data, len(lists))
+	var walk func(level int) error
+	walk = func(level int) error {
+		if level == len(lists) {
+			include, value, err := forCollect(names, values, guard, body, env)
+			if include {
+				collected = append(collected, value)
+			}
+			return err
+		}
+		for _, v := range lists[level] {
+			values[level] = v
+			if err := walk(level + 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = walk(0)
+	if err != nil {
+		return
+	}
+	return ArrayToList(collected), nil
+}
+
 func ApplyImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	f := Car(args)
 
@@ -452,20 +918,15 @@ func ApplyImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 		return
 	}
 
+	// Any arguments before the last are spread in front of it: (apply f a b '(c d)) is (f a b c d)
 	ary := ToArray(Cdr(args))
-	var argList *Data
-	if ListP(ary[len(ary)-1]) {
-		if len(ary) > 1 {
-			argList = ArrayToListWithTail(ary[0:len(ary)-1], ary[len(ary)-1])
-		} else {
-			argList = ary[0]
-		}
-	} else {
+	last := ary[len(ary)-1]
+	if !ListP(last) {
 		err = ProcessError("The last argument to apply must be a list", env)
 		return
 	}
 
-	return ApplyWithoutEval(f, argList, env)
+	return ApplyWithoutEval(f, ArrayToList(append(ary[0:len(ary)-1], ToArray(last)...)), env)
 }
 
 func ChainImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -0,0 +1,202 @@
+// Copyright 2014 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file contains the promise and stream primitive functions.
+
+package golisp
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// A promise computes its value the first time it is forced and remembers it.
+type Promise struct {
+	Thunk  func() (*Data, error)
+	Value  *Data
+	Forced bool
+	Mutex  sync.Mutex
+}
+
+func RegisterStreamPrimitives() {
+	MakeSpecialForm("delay", "1", DelayImpl)
+	MakePrimitiveFunction("force", "1", ForceImpl)
+	MakePrimitiveFunction("promise?", "1", PromisePImpl)
+
+	Global.BindToProtected(Intern("the-empty-stream"), EmptyCons())
+	MakeSpecialForm("stream-cons", "2", StreamConsImpl)
+	MakePrimitiveFunction("stream-pair?", "1", StreamPairPImpl)
+	MakePrimitiveFunction("stream-null?", "1", NilPImpl)
+	MakePrimitiveFunction("stream-car", "1", StreamCarImpl)
+	MakePrimitiveFunction("stream-cdr", "1", StreamCdrImpl)
+	MakePrimitiveFunction("stream-take", "2", StreamTakeImpl)
+	MakePrimitiveFunction("stream-map", ">=2", StreamMapImpl)
+}
+
+func PromiseWithThunk(thunk func() (*Data, error)) *Data {
+	return ObjectWithTypeAndValue("Promise", unsafe.Pointer(&Promise{Thunk: thunk}))
+}
+
+func PromiseP(d *Data) bool {
+	return ObjectP(d) && ObjectType(d) == "Promise"
+}
+
+// Force returns the value of a promise, computing it if necessary. Anything
+// that isn't a promise is its own value. The lock isn't held while the thunk
+// runs, so a thunk can force its own promise; if the promise is computed more
+// than once, the first value to be finished is the one it keeps.
+func Force(d *Data) (result *Data, err error) {
+	if !PromiseP(d) {
+		return d, nil
+	}
+
+	p := (*Promise)(ObjectValue(d))
+	p.Mutex.Lock()
+	if p.Forced {
+		p.Mutex.Unlock()
+		return p.Value, nil
+	}
+	thunk := p.Thunk
+	p.Mutex.Unlock()
+
+	value, err := thunk()
+	if err != nil {
+		return
+	}
+
+	p.Mutex.Lock()
+	defer p.Mutex.Unlock()
+	if !p.Forced {
+		p.Value = value
+		p.Forced = true
+		p.Thunk = nil
+	}
+	return p.Value, nil
+}
+
+func DelayImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	sexpr := Car(args)
+	return PromiseWithThunk(func() (*Data, error) {
+		return Eval(sexpr, env)
+	}), nil
+}
+
+func ForceImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return Force(Car(args))
+}
+
+func PromisePImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return BooleanWithValue(PromiseP(Car(args))), nil
+}
+
+func StreamPairP(d *Data) bool {
+	return PairP(d) && NotNilP(d) && PromiseP(Cdr(d))
+}
+
+func streamArg(name string, s *Data, env *SymbolTableFrame) (err error) {
+	if !StreamPairP(s) {
+		err = ProcessError(fmt.Sprintf("%s requires a non-empty stream, but received %s.", name, String(s)), env)
+	}
+	return
+}
+
+func StreamConsImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	head, err := Eval(Car(args), env)
+	if err != nil {
+		return
+	}
+	tail, err := DelayImpl(Cdr(args), env)
+	if err != nil {
+		return
+	}
+	return Cons(head, tail), nil
+}
+
+func StreamPairPImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return BooleanWithValue(StreamPairP(Car(args))), nil
+}
+
+func StreamCarImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	s := Car(args)
+	if err = streamArg("stream-car", s, env); err != nil {
+		return
+	}
+	return Car(s), nil
+}
+
+func StreamCdrImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	s := Car(args)
+	if err = streamArg("stream-cdr", s, env); err != nil {
+		return
+	}
+	return Force(Cdr(s))
+}
+
+// Returns a list of the first n elements of a stream, or all of them if the stream is shorter.
+func StreamTakeImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	s := First(args)
+	n := Second(args)
+	if !IntegerP(n) || IntegerValue(n) < 0 {
+		err = ProcessError(fmt.Sprintf("stream-take requires a non-negative integer count, but received %s.", String(n)), env)
+		return
+	}
+
+	items := make([]*Data, 0, IntegerValue(n))
+	for i := int64(0); i < IntegerValue(n) && NotNilP(s); i++ {
+		if err = streamArg("stream-take", s, env); err != nil {
+			return
+		}
+		items = append(items, Car(s))
+		if i+1 < IntegerValue(n) {
+			s, err = Force(Cdr(s))
+			if err != nil {
+				return
+			}
+		}
+	}
+	return ArrayToList(items), nil
+}
+
+// Maps f over the elements of the streams taken together, ending with the
+// shortest of them.
+func streamMap(f *Data, streams []*Data, env *SymbolTableFrame) (result *Data, err error) {
+	heads := make([]*Data, 0, len(streams))
+	for _, s := range streams {
+		if NilP(s) {
+			return EmptyCons(), nil
+		}
+		if err = streamArg("stream-map", s, env); err != nil {
+			return
+		}
+		heads = append(heads, Car(s))
+	}
+
+	head, err := ApplyWithoutEval(f, ArrayToList(heads), env)
+	if err != nil {
+		return
+	}
+	tail := PromiseWithThunk(func() (*Data, error) {
+		rests := make([]*Data, 0, len(streams))
+		for _, s := range streams {
+			rest, err := Force(Cdr(s))
+			if err != nil {
+				return nil, err
+			}
+			rests = append(rests, rest)
+		}
+		return streamMap(f, rests, env)
+	})
+	return Cons(head, tail), nil
+}
+
+func StreamMapImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	f := First(args)
+	if !FunctionOrPrimitiveP(f) {
+		err = ProcessError(fmt.Sprintf("stream-map requires a function as it's first argument, but received %s.", String(f)), env)
+		return
+	}
+	return streamMap(f, ToArray(Cdr(args)), env)
+}
@@ -21,6 +21,9 @@ const (
 func RegisterStringPrimitives() {
 	MakePrimitiveFunction("string-split", "2", StringSplitImpl)
 	MakePrimitiveFunction("string-join", "1|2", StringJoinImpl)
+	MakePrimitiveFunction("string-append", "*", StringAppendImpl)
+	MakePrimitiveFunction("string-concat", "*", StringAppendImpl)
+	MakePrimitiveFunction("symbol-append", ">=1", SymbolAppendImpl)
 	MakePrimitiveFunction("string-trim", "1|2", StringTrimImpl)
 	MakePrimitiveFunction("string-trim-left", "1|2", StringTrimLeftImpl)
 	MakePrimitiveFunction("string-trim-right", "1|2", StringTrimRightImpl)
@@ -30,6 +33,7 @@ func RegisterStringPrimitives() {
 	MakePrimitiveFunction("string-downcase!", "1", StringDowncaseBangImpl)
 	MakePrimitiveFunction("string-capitalize", "1", StringCapitalizeImpl)
 	MakePrimitiveFunction("string-capitalize!", "1", StringCapitalizeBangImpl)
+	MakePrimitiveFunction("string-reverse", "1", StringReverseImpl)
 	MakePrimitiveFunction("string-length", "1", StringLengthImpl)
 	MakePrimitiveFunction("string-null?", "1", StringNullImpl)
 	MakePrimitiveFunction("substring", "3", SubstringImpl)
@@ -51,10 +55,12 @@ func RegisterStringPrimitives() {
 	MakePrimitiveFunction("parse", "1", ParseImpl)
 }
 
+// Splits like Go's strings.Split: an empty separator splits the string into
+// its characters, and splitting "" gives a list of one empty string.
 func StringSplitImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	theString := Car(args)
 	if !StringP(theString) {
-		err = ProcessError(fmt.Sprintf("trim requires a string but was given %s.", String(theString)), env)
+		err = ProcessError(fmt.Sprintf("string-split requires a string but was given %s.", String(theString)), env)
 		return
 	}
 
@@ -102,6 +108,35 @@ func StringJoinImpl(args *Data, env *SymbolTableFrame) (result *Data, err error)
 	return StringWithValue(joinedString), nil
 }
 
+func appendNames(name string, args *Data, env *SymbolTableFrame) (result string, err error) {
+	parts := make([]string, 0, Length(args))
+	for c := args; NotNilP(c); c = Cdr(c) {
+		val := Car(c)
+		if !StringP(val) && !SymbolP(val) {
+			err = ProcessError(fmt.Sprintf("%s requires strings or symbols but was given %s.", name, String(val)), env)
+			return
+		}
+		parts = append(parts, StringValue(val))
+	}
+	return strings.Join(parts, ""), nil
+}
+
+func StringAppendImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	str, err := appendNames("string-append", args, env)
+	if err != nil {
+		return
+	}
+	return StringWithValue(str), nil
+}
+
+func SymbolAppendImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	name, err := appendNames("symbol-append", args, env)
+	if err != nil {
+		return
+	}
+	return Intern(name), nil
+}
+
 func doTrim(lrb int, args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	theString := Car(args)
 
@@ -164,6 +199,20 @@ func StringUpcaseBangImpl(args *Data, env *SymbolTableFrame) (result *Data, err
 	return SetStringValue(theString, strings.ToUpper(StringValue(theString))), nil
 }
 
+// Reverses the characters rather than the bytes so multi-byte characters stay intact.
+func StringReverseImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	theString := Car(args)
+	if !StringP(theString) {
+		err = ProcessError(fmt.Sprintf("string-reverse requires a string but was given %s.", String(theString)), env)
+		return
+	}
+	runes := []rune(StringValue(theString))
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return StringWithValue(string(runes)), nil
+}
+
 func StringDowncaseImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	theString := Car(args)
 	if !StringP(theString) {
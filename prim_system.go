@@ -8,18 +8,28 @@
 package golisp
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
 	"os/exec"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var symbolCounts map[string]int = make(map[string]int)
 var symbolCountsMutex sync.Mutex
 
+// The symbols made by gensym aren't interned and are only equal to
+// themselves, not to a symbol read with the same name.
+func GeneratedSymbolP(d *Data) bool {
+	return SymbolP(d) && d.Generated
+}
+
 func RegisterSystemPrimitives() {
 	MakePrimitiveFunction("sleep", "1", SleepImpl)
 	MakePrimitiveFunction("millis", "0", MillisImpl)
@@ -31,27 +41,77 @@ func RegisterSystemPrimitives() {
 	MakePrimitiveFunction("gensym", "0|1", GensymImpl)
 	MakePrimitiveFunction("gensym-naked", "0|1", GensymNakedImpl)
 	MakePrimitiveFunction("eval", "1|2", EvalImpl)
+	MakePrimitiveFunctionFull("doc", "1", "Returns the documentation string of a primitive or function.", []uint32{PrimitiveTypeMask | FunctionTypeMask}, StringTypeMask, DocImpl)
+	MakePrimitiveFunction("type-signature", "1", TypeSignatureImpl)
+	MakePrimitiveFunction("function-name", "1", FunctionNameImpl)
+	MakePrimitiveFunction("function-documentation", "1", FunctionDocumentationImpl)
+	MakePrimitiveFunction("function-arity", "1", FunctionArityImpl)
+	MakePrimitiveFunction("function-params", "1", FunctionParamsImpl)
 
 	MakeRestrictedPrimitiveFunction("load", "1", LoadFileImpl)
 	MakeRestrictedPrimitiveFunction("global-eval", "1", GlobalEvalImpl)
 	MakeRestrictedPrimitiveFunction("panic!", "1", PanicImpl)
-	MakePrimitiveFunction("error", "1", ErrorImpl)
+	MakePrimitiveFunction("error", ">=1", ErrorImpl)
+	MakeSpecialForm("assert", "1|2", AssertImpl)
 	MakeSpecialForm("on-error", "2|3", OnErrorImpl)
+	MakePrimitiveFunction("raise", "1", RaiseImpl)
+	MakeSpecialForm("guard", ">=1", GuardImpl)
+	MakeSpecialForm("catch", ">=1", CatchImpl)
+	MakePrimitiveFunction("throw", "2", ThrowImpl)
+	MakeSpecialForm("unwind-protect", ">=2", UnwindProtectImpl)
 
 	MakeSpecialForm("time", "1", TimeImpl)
+	MakeSpecialForm("time-it", ">=1", TimeItImpl)
+	MakePrimitiveFunction("allocations-during", "1", AllocationsDuringImpl)
 	MakeSpecialForm("profile", "1|2", ProfileImpl)
+	MakeRestrictedPrimitiveFunction("profile-dump", "1|2", ProfileDumpImpl)
+	MakePrimitiveFunction("profile-reset", "0", ProfileResetImpl)
+	MakePrimitiveFunction("reset-profile", "0", ProfileResetImpl)
+	MakePrimitiveFunction("profile-enable", "0", ProfileEnableImpl)
+	MakePrimitiveFunction("profile-disable", "0", ProfileDisableImpl)
 
 	MakeRestrictedPrimitiveFunction("exec", ">=1", ExecImpl)
 }
 
+// Evaluates each form in a file in the global environment, returning the
+// value of the last one. Relative paths are relative to the working directory.
 func LoadFileImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	filename := Car(args)
 	if !StringP(filename) {
 		err = ProcessError("Filename must be a string", env)
 		return
 	}
+	fname := StringValue(filename)
+
+	src, err := ReadFile(fname)
+	if err != nil {
+		err = ProcessError(fmt.Sprintf("load could not read %s: %s", fname, err), env)
+		return
+	}
 
-	return ProcessFile(StringValue(filename))
+	s := NewTokenizerFromString(src)
+	var sexpr, previous *Data
+	var eof bool
+	for {
+		sexpr, eof, err = parseExpression(s)
+		if err != nil {
+			if previous == nil {
+				err = ProcessError(fmt.Sprintf("load could not parse the first form of %s: %s", fname, err), env)
+			} else {
+				err = ProcessError(fmt.Sprintf("load could not parse the form after %s in %s: %s", String(previous), fname, err), env)
+			}
+			return
+		}
+		if eof {
+			return
+		}
+		result, err = Eval(sexpr, Global)
+		if err != nil {
+			err = ProcessError(fmt.Sprintf("load failed in %s evaluating %s: %s", fname, String(sexpr), err), env)
+			return
+		}
+		previous = sexpr
+	}
 }
 
 var goodbyes []string = []string{
@@ -74,8 +134,51 @@ func PanicImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	panic(String(Car(args)))
 }
 
+// (error control-string arg...) fails with the message format makes from its
+// arguments. Given a single argument, the message is a string as it is, or
+// anything else as write prints it.
 func ErrorImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
-	return nil, ProcessError(String(Car(args)), env)
+	if Length(args) == 1 {
+		if StringP(Car(args)) {
+			return nil, ProcessError(StringValue(Car(args)), env)
+		}
+		return nil, ProcessError(String(Car(args)), env)
+	}
+	if !StringP(Car(args)) {
+		err = ProcessError(fmt.Sprintf("error requires a control string when given arguments, but received %s.", String(Car(args))), env)
+		return
+	}
+
+	message, err := FormatImpl(Cons(LispFalse, args), env)
+	if err != nil {
+		return
+	}
+	return nil, ProcessError(StringValue(message), env)
+}
+
+// (assert expr message) returns the value of expr unless it is false, in
+// which case it fails with an error naming expr and including the optional
+// message, which is evaluated only then.
+func AssertImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, err = Eval(Car(args), env)
+	if err != nil || BooleanValue(result) {
+		return
+	}
+
+	text := fmt.Sprintf("Assertion failed: %s", String(Car(args)))
+	if Length(args) == 2 {
+		var message *Data
+		message, err = Eval(Cadr(args), env)
+		if err != nil {
+			return
+		}
+		if StringP(message) {
+			text = fmt.Sprintf("%s: %s", text, StringValue(message))
+		} else {
+			text = fmt.Sprintf("%s: %s", text, String(message))
+		}
+	}
+	return nil, ProcessError(text, env)
 }
 
 func OnErrorImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -97,6 +200,10 @@ func OnErrorImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 		}
 	}
 
+	if _, ok := errThrown.(*ThrownError); ok {
+		return nil, errThrown
+	}
+
 	f, err := Eval(Cadr(args), env)
 	if err != nil {
 		return
@@ -111,6 +218,158 @@ func OnErrorImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return handler.Apply(InternalMakeList(errString), env)
 }
 
+// A RaisedError carries the object given to raise out through the evaluator
+// so that guard can hand it to its clauses.
+type RaisedError struct {
+	Value   *Data
+	Message string
+}
+
+func (self *RaisedError) Error() string {
+	return self.Message
+}
+
+func RaiseImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return nil, &RaisedError{Value: Car(args), Message: fmt.Sprintf("Raised %s", String(Car(args)))}
+}
+
+// A ThrownError carries the value given to throw out through the evaluator to
+// the catch with the same tag. guard and on-error let it pass.
+type ThrownError struct {
+	Tag     *Data
+	Value   *Data
+	Message string
+}
+
+func (self *ThrownError) Error() string {
+	return self.Message
+}
+
+// Returns an error with the given message in place of err's, keeping the
+// object a raise or throw carries so that guard and catch still see it.
+func errorWithMessage(err error, message string) error {
+	switch e := err.(type) {
+	case *RaisedError:
+		return &RaisedError{Value: e.Value, Message: message}
+	case *ThrownError:
+		return &ThrownError{Tag: e.Tag, Value: e.Value, Message: message}
+	default:
+		return errors.New(message)
+	}
+}
+
+// (catch tag body...) evaluates body, returning the value of the last form or,
+// if body throws to tag, the value thrown. Throws to other tags pass through.
+// The body is evaluated in a frame carrying the tag, so that throw can find it
+// by following the frames it was called through.
+func CatchImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	tag, err := Eval(Car(args), env)
+	if err != nil {
+		return
+	}
+
+	localEnv := NewSymbolTableFrameBelow(env, "catch")
+	localEnv.Previous = env
+	localEnv.CatchTag = tag
+	result, err = evaluateBody(Cdr(args), localEnv)
+	if thrown, ok := err.(*ThrownError); ok && IsEqual(thrown.Tag, tag) {
+		return thrown.Value, nil
+	}
+	return
+}
+
+// Reports whether a catch for tag is being evaluated in env or one of the
+// frames it was called from.
+func catchActive(tag *Data, env *SymbolTableFrame) bool {
+	for frame := env; frame != nil; frame = frame.Previous {
+		if frame.CatchTag != nil && IsEqual(frame.CatchTag, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func ThrowImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	tag := First(args)
+	if !catchActive(tag, env) {
+		err = ProcessError(fmt.Sprintf("throw to %s, but there is no catch for it.", String(tag)), env)
+		return
+	}
+	return nil, &ThrownError{Tag: tag, Value: Second(args), Message: fmt.Sprintf("Thrown %s to %s", String(Second(args)), String(tag))}
+}
+
+// (unwind-protect protected cleanup...) evaluates protected and then the
+// cleanup forms, even if protected raised an error or threw. The result is
+// protected's value or error; an error in the cleanup is only reported when
+// protected succeeded.
+func UnwindProtectImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	result, err = Eval(Car(args), env)
+	_, cleanupErr := evaluateBody(Cdr(args), env)
+	if err == nil && cleanupErr != nil {
+		return nil, cleanupErr
+	}
+	return
+}
+
+// The condition a guard sees: the object given to raise, or the message
+// string of any other error (as on-error passes to its handler).
+func conditionFor(err error) *Data {
+	if raised, ok := err.(*RaisedError); ok {
+		return raised.Value
+	}
+	return StringWithValue(err.Error())
+}
+
+// (guard (var clause...) body...) evaluates body. If that fails, var is bound
+// to the condition and the clauses are tried like those of cond. If none of
+// them apply the original error is passed on.
+func GuardImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	spec := Car(args)
+	if !PairP(spec) || !SymbolP(Car(spec)) {
+		err = ProcessError(fmt.Sprintf("guard requires (var clause...) as it's first argument, but received %s.", String(spec)), env)
+		return
+	}
+
+	result, errThrown := evaluateBody(Cdr(args), env)
+	if errThrown == nil {
+		return
+	}
+	if _, ok := errThrown.(*ThrownError); ok {
+		return nil, errThrown
+	}
+
+	localEnv := NewSymbolTableFrameBelow(env, "guard")
+	localEnv.Previous = env
+	_, err = localEnv.BindLocallyTo(Car(spec), conditionFor(errThrown))
+	if err != nil {
+		return
+	}
+
+	var condition *Data
+	for c := Cdr(spec); NotNilP(c); c = Cdr(c) {
+		clause := Car(c)
+		if !PairP(clause) {
+			err = ProcessError("guard expects clauses that are lists", env)
+			return
+		}
+		if IsEqual(Car(clause), Intern("else")) {
+			return evaluateBody(Cdr(clause), localEnv)
+		}
+		condition, err = Eval(Car(clause), localEnv)
+		if err != nil {
+			return
+		}
+		if BooleanValue(condition) {
+			if NilP(Cdr(clause)) {
+				return condition, nil
+			}
+			return evaluateBody(Cdr(clause), localEnv)
+		}
+	}
+
+	return nil, errThrown
+}
+
 func QuitImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	if IsInteractive || DebugEvalInDebugRepl {
 		WriteHistoryToFile(".golisp_history")
@@ -149,7 +408,7 @@ func concatStringForms(args *Data) (str string) {
 }
 
 func WriteLineImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
-	println(concatStringForms(args))
+	err = writeOutput(concatStringForms(args)+"\n", env)
 	return
 }
 
@@ -178,6 +437,35 @@ func TimeImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return
 }
 
+// Like time, but returns the value of the last form and prints the elapsed
+// time, as "elapsed: 1.234ms", so it can wrap an expression in place.
+func TimeItImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	startTime := time.Now()
+	result, err = evaluateBody(args, env)
+	writeOutput(fmt.Sprintf("elapsed: %s\n", time.Since(startTime)), env)
+	return
+}
+
+// Counts the cons cells allocated by evaluating the thunk. Cells allocated by
+// other goroutines at the same time, including ones the thunk forks, aren't
+// counted.
+func AllocationsDuringImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	thunk := Car(args)
+	if !FunctionOrPrimitiveP(thunk) {
+		err = ProcessError(fmt.Sprintf("allocations-during requires a function, but received %s.", String(thunk)), env)
+		return
+	}
+
+	var count int64
+	stop := startCountingConsAllocations(&count)
+	_, err = ApplyWithoutEval(thunk, nil, env)
+	stop()
+	if err != nil {
+		return
+	}
+	return IntegerWithValue(atomic.LoadInt64(&count)), nil
+}
+
 func InternImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	sym := Car(args)
 	if !StringP(sym) {
@@ -214,12 +502,19 @@ func gensymHelper(primitiveName string, args *Data, env *SymbolTableFrame) (pref
 	return
 }
 
+// Names already in use by interned symbols are skipped.
 func GensymImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
-	prefix, count, err := gensymHelper("gensym", args, env)
-	if err != nil {
-		return
+	var prefix, name string
+	var count int
+	for name == "" || InternedP(name) {
+		prefix, count, err = gensymHelper("gensym", args, env)
+		if err != nil {
+			return
+		}
+		name = fmt.Sprintf("%s-%d", prefix, count)
 	}
-	result = SymbolWithName(fmt.Sprintf("%s-%d", prefix, count))
+	result = SymbolWithName(name)
+	result.Generated = true
 	return
 }
 
@@ -247,6 +542,68 @@ func EvalImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return Eval(sexpr, evalEnv)
 }
 
+func DocImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	f := Car(args)
+	if FunctionP(f) {
+		return StringWithValue(FunctionValue(f).DocString), nil
+	}
+	return StringWithValue(PrimitiveValue(f).DocString), nil
+}
+
+// Returns the declared type signature of a primitive, or nil if it has none.
+func TypeSignatureImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	f := Car(args)
+	if !FunctionOrPrimitiveP(f) {
+		err = ProcessError(fmt.Sprintf("type-signature requires a function, but received %s.", String(f)), env)
+		return
+	}
+	if PrimitiveP(f) {
+		return PrimitiveValue(f).TypeSpec(), nil
+	}
+	return
+}
+
+func functionArg(name string, args *Data, env *SymbolTableFrame) (f *Function, err error) {
+	d := Car(args)
+	if !FunctionP(d) {
+		err = ProcessError(fmt.Sprintf("%s requires a function, but received %s.", name, String(d)), env)
+		return
+	}
+	return FunctionValue(d), nil
+}
+
+func FunctionNameImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	f, err := functionArg("function-name", args, env)
+	if err != nil {
+		return
+	}
+	return StringWithValue(f.Name), nil
+}
+
+func FunctionDocumentationImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	if _, err = functionArg("function-documentation", args, env); err != nil {
+		return
+	}
+	return DocImpl(args, env)
+}
+
+// Returns a list of the number of required parameters and whether the function takes more.
+func FunctionArityImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	f, err := functionArg("function-arity", args, env)
+	if err != nil {
+		return
+	}
+	return InternalMakeList(IntegerWithValue(int64(f.RequiredArgCount)), BooleanWithValue(f.VarArgs)), nil
+}
+
+func FunctionParamsImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	f, err := functionArg("function-params", args, env)
+	if err != nil {
+		return
+	}
+	return f.Params, nil
+}
+
 func GlobalEvalImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return Eval(Car(args), Global)
 }
@@ -268,6 +625,63 @@ func ProfileImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return
 }
 
+// (profile-dump path format) writes the events recorded by profile since the
+// last profile-reset to a file, as 'json or 'chrome-trace, returning how many there were.
+// Without a format it writes the per-function summary and call tree of DumpProfileJSON.
+func ProfileDumpImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	path := First(args)
+	if !StringP(path) {
+		err = ProcessError(fmt.Sprintf("profile-dump requires a string filename, but received %s.", String(path)), env)
+		return
+	}
+
+	format := Second(args)
+	var write func(io.Writer, []ProfileEvent) error
+	switch {
+	case Length(args) == 1:
+		write = func(w io.Writer, events []ProfileEvent) error {
+			return json.NewEncoder(w).Encode(SummarizeProfile(events))
+		}
+	case IsEqual(format, Intern("json")):
+		write = WriteProfileJson
+	case IsEqual(format, Intern("chrome-trace")):
+		write = WriteProfileChromeTrace
+	default:
+		err = ProcessError(fmt.Sprintf("profile-dump requires a format of json or chrome-trace, but received %s.", String(format)), env)
+		return
+	}
+
+	f, err := os.Create(StringValue(path))
+	if err != nil {
+		err = ProcessError(fmt.Sprintf("profile-dump could not create %s: %s", StringValue(path), err), env)
+		return
+	}
+	defer f.Close()
+
+	events := ProfileEvents()
+	err = write(f, events)
+	if err != nil {
+		err = ProcessError(fmt.Sprintf("profile-dump could not write %s: %s", StringValue(path), err), env)
+		return
+	}
+	return IntegerWithValue(int64(len(events))), nil
+}
+
+func ProfileResetImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	ResetProfile()
+	return
+}
+
+func ProfileEnableImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	EnableProfiling()
+	return
+}
+
+func ProfileDisableImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	DisableProfiling()
+	return
+}
+
 func ExecImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	if !StringP(First(args)) {
 		err = ProcessError(fmt.Sprintf("exec requires a string command, but received %s.", String(First(args))), env)
@@ -27,6 +27,58 @@ func RegisterTypePredicatePrimitives() {
 	MakePrimitiveFunction("bytearray?", "1", IsByteArrayImpl)
 	MakePrimitiveFunction("port?", "1", IsPortImpl)
 	MakePrimitiveFunction("boolean?", "1", IsBooleanImpl)
+	MakePrimitiveFunction("type-of", "1", TypeOfImpl)
+}
+
+// The name type-of returns for a value.
+func TypeOfName(d *Data) string {
+	switch TypeOf(d) {
+	case NilType:
+		return "nil"
+	case ConsCellType:
+		if NilP(d) {
+			return "nil"
+		}
+		return "list"
+	case AlistType:
+		return "alist"
+	case AlistCellType:
+		return "pair"
+	case IntegerType:
+		return "integer"
+	case FloatType:
+		return "float"
+	case BigIntegerType:
+		return "big-integer"
+	case BooleanType:
+		return "boolean"
+	case StringType:
+		return "string"
+	case SymbolType:
+		return "symbol"
+	case FunctionType:
+		return "function"
+	case MacroType:
+		return "macro"
+	case PrimitiveType:
+		return "primitive"
+	case FrameType:
+		return "frame"
+	case EnvironmentType:
+		return "environment"
+	case PortType:
+		return "port"
+	case BoxedObjectType:
+		switch ObjectType(d) {
+		case "[]byte":
+			return "bytearray"
+		case "HashTable":
+			return "hash-table"
+		}
+		return "object"
+	default:
+		return "unknown"
+	}
 }
 
 func IsAtomImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -59,7 +111,7 @@ func IsStringImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 }
 
 func IsIntegerImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
-	return BooleanWithValue(IntegerP(Car(args))), nil
+	return BooleanWithValue(ExactIntegerP(Car(args))), nil
 }
 
 func IsNumberImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
@@ -93,3 +145,7 @@ func IsPortImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 func IsBooleanImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	return BooleanWithValue(BooleanP(Car(args))), nil
 }
+
+func TypeOfImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return Intern(TypeOfName(Car(args))), nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2014 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file contains the multiple value primitive functions.
+
+package golisp
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// A single value is always represented by the value itself; any other number
+// of values (including none) is boxed as a "MultipleValues" object.
+
+func RegisterValuesPrimitives() {
+	MakePrimitiveFunction("values", "*", ValuesImpl)
+	MakePrimitiveFunction("list->values", "1", ListToValuesImpl)
+	MakePrimitiveFunction("call-with-values", "2", CallWithValuesImpl)
+	MakeSpecialForm("let-values", ">=1", LetValuesImpl)
+	MakeSpecialForm("define-values", "2", DefineValuesImpl)
+}
+
+func ValuesWithArray(values []*Data) *Data {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return ObjectWithTypeAndValue("MultipleValues", unsafe.Pointer(&values))
+}
+
+func MultipleValuesP(d *Data) bool {
+	return ObjectP(d) && ObjectType(d) == "MultipleValues"
+}
+
+// ValuesToArray returns the values a result represents.
+func ValuesToArray(d *Data) []*Data {
+	if MultipleValuesP(d) {
+		return *(*[]*Data)(ObjectValue(d))
+	}
+	return []*Data{d}
+}
+
+func ValuesImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	return ValuesWithArray(ToArray(args)), nil
+}
+
+func ListToValuesImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	l := Car(args)
+	if !ListP(l) {
+		err = ProcessError(fmt.Sprintf("list->values requires a list, but received %s.", String(l)), env)
+		return
+	}
+	return ValuesWithArray(ToArray(l)), nil
+}
+
+func CallWithValuesImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	producer := First(args)
+	if !FunctionOrPrimitiveP(producer) {
+		err = ProcessError(fmt.Sprintf("call-with-values requires a function as its first argument, but received %s.", String(producer)), env)
+		return
+	}
+
+	consumer := Second(args)
+	if !FunctionOrPrimitiveP(consumer) {
+		err = ProcessError(fmt.Sprintf("call-with-values requires a function as its second argument, but received %s.", String(consumer)), env)
+		return
+	}
+
+	values, err := ApplyWithoutEval(producer, nil, env)
+	if err != nil {
+		return
+	}
+	return ApplyWithoutEval(consumer, ArrayToList(ValuesToArray(values)), env)
+}
+
+// Binds each symbol in formals to the corresponding value of a producer's
+// result, erroring unless there are exactly as many values as names.
+func bindValues(name string, formals *Data, values *Data, bindEnv *SymbolTableFrame, env *SymbolTableFrame) (err error) {
+	if !ListP(formals) {
+		err = ProcessError(fmt.Sprintf("%s requires a list of names to bind, but received %s.", name, String(formals)), env)
+		return
+	}
+	for c := formals; NotNilP(c); c = Cdr(c) {
+		if !SymbolP(Car(c)) {
+			err = ProcessError(fmt.Sprintf("%s can only bind symbols, but received %s.", name, String(Car(c))), env)
+			return
+		}
+	}
+
+	items := ValuesToArray(values)
+	if len(items) != Length(formals) {
+		err = ProcessError(fmt.Sprintf("%s expected %d values for %s, but received %d.", name, Length(formals), String(formals), len(items)), env)
+		return
+	}
+
+	i := 0
+	for c := formals; NotNilP(c); c = Cdr(c) {
+		_, err = bindEnv.BindLocallyTo(Car(c), items[i])
+		if err != nil {
+			return
+		}
+		i++
+	}
+	return
+}
+
+// (let-values (((name...) producer) ...) body...) evaluates each producer in
+// the enclosing environment and binds its values to the names for the body.
+func LetValuesImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	bindings := Car(args)
+	if !ListP(bindings) {
+		err = ProcessError(fmt.Sprintf("let-values requires a list of bindings as it's first argument, but received %s.", String(bindings)), env)
+		return
+	}
+
+	localEnv := NewSymbolTableFrameBelow(env, "let-values")
+	localEnv.Previous = env
+	var values *Data
+	for c := bindings; NotNilP(c); c = Cdr(c) {
+		binding := Car(c)
+		if !PairP(binding) || Length(binding) != 2 {
+			err = ProcessError(fmt.Sprintf("let-values bindings must be of the form ((name...) producer), but received %s.", String(binding)), env)
+			return
+		}
+		values, err = Eval(Second(binding), env)
+		if err != nil {
+			return
+		}
+		err = bindValues("let-values", First(binding), values, localEnv, env)
+		if err != nil {
+			return
+		}
+	}
+
+	return evaluateBody(Cdr(args), localEnv)
+}
+
+func DefineValuesImpl(args *Data, env *SymbolTableFrame) (result *Data, err error) {
+	values, err := Eval(Second(args), env)
+	if err != nil {
+		return
+	}
+	err = bindValues("define-values", First(args), values, env, env)
+	return
+}
@@ -35,8 +35,11 @@ type PrimitiveFunction struct {
 	Special         bool
 	ArgRestrictions []ArgRestriction
 	ArgTypes        []uint32
+	ReturnType      uint32
+	DocString       string
 	Body            func(d *Data, env *SymbolTableFrame) (*Data, error)
 	IsRestricted    bool
+	Memoized        *MemoizedFunction // the cache of a function made by memoize
 }
 
 func MakePrimitiveFunction(name string, argCount string, function func(*Data, *SymbolTableFrame) (*Data, error)) {
@@ -46,6 +49,93 @@ func MakePrimitiveFunction(name string, argCount string, function func(*Data, *S
 	Global.BindToProtected(sym, PrimitiveWithNameAndFunc(name, f))
 }
 
+// MakePrimitiveFunctionFull registers a primitive along with its documentation
+// and type signature. argTypes holds a type mask (e.g. IntegerTypeMask|FloatTypeMask)
+// per argument; arguments past the end of argTypes take the type of the last entry.
+// An empty argTypes, or a zero retType, leaves that part unchecked.
+func MakePrimitiveFunctionFull(name string, argCount string, doc string, argTypes []uint32, retType uint32, function func(*Data, *SymbolTableFrame) (*Data, error)) {
+	f := &PrimitiveFunction{Name: name, Special: false, Body: function, IsRestricted: false, DocString: doc, ArgTypes: argTypes, ReturnType: retType}
+	f.parseNumArgs(argCount)
+	sym := Intern(name)
+	Global.BindToProtected(sym, PrimitiveWithNameAndFunc(name, f))
+}
+
+// MakeTypedPrimitiveFunction is MakePrimitiveFunctionFull with the types
+// written as strings. The grammar is:
+//
+//	argTypes = { union } separated by spaces, one union per argument position
+//	union    = typeName { "|" typeName }
+//	typeName = a TypeName lowercased with spaces as dashes (integer, float,
+//	           string, list, association-list, go-object, ...) or anything
+//
+// e.g. "integer|float string". Type names are never numbers, so a union like
+// "integer|float" can't be mistaken for an argCount like "1|2", which lists
+// alternative numbers of arguments. An empty retType leaves the result
+// unchecked. Since this is used at registration, a bad spec panics.
+func MakeTypedPrimitiveFunction(name string, argCount string, doc string, argTypes string, retType string, function func(*Data, *SymbolTableFrame) (*Data, error)) {
+	argMasks, err := ParseArgTypes(argTypes)
+	if err != nil {
+		panic(fmt.Sprintf("%s: %s", name, err))
+	}
+	var retMask uint32
+	if retType != "" {
+		retMask, err = ParseTypeMask(retType)
+		if err != nil {
+			panic(fmt.Sprintf("%s: %s", name, err))
+		}
+	}
+	MakePrimitiveFunctionFull(name, argCount, doc, argMasks, retMask, function)
+}
+
+// ParseArgTypes parses a space separated list of type unions, one per
+// argument, into their masks.
+func ParseArgTypes(spec string) (masks []uint32, err error) {
+	for _, union := range strings.Fields(spec) {
+		var mask uint32
+		mask, err = ParseTypeMask(union)
+		if err != nil {
+			return nil, err
+		}
+		masks = append(masks, mask)
+	}
+	return
+}
+
+// ParseTypeMask parses a union of type names such as "integer|float",
+// ORing their masks together.
+func ParseTypeMask(union string) (mask uint32, err error) {
+	for _, name := range strings.Split(union, "|") {
+		if name == "anything" {
+			mask |= AnyTypeMask
+			continue
+		}
+		found := false
+		for t := uint8(NilType); t <= BigIntegerType; t++ {
+			if strings.Replace(strings.ToLower(TypeName(t)), " ", "-", -1) == name {
+				mask |= 1 << t
+				found = true
+				break
+			}
+		}
+		if !found {
+			var n int
+			if _, scanErr := fmt.Sscanf(name, "%d", &n); scanErr == nil {
+				return 0, fmt.Errorf("%s in the type %s is a number of arguments; types are named, e.g. integer|float", name, union)
+			}
+			return 0, fmt.Errorf("%s in the type %s is not a type name", name, union)
+		}
+	}
+	return
+}
+
+// RegisterPrimitive registers a primitive whose implementation takes its
+// (evaluated) arguments as a slice rather than a list.
+func RegisterPrimitive(name string, argCount string, function func([]*Data, *SymbolTableFrame) (*Data, error)) {
+	MakePrimitiveFunction(name, argCount, func(args *Data, env *SymbolTableFrame) (*Data, error) {
+		return function(ToArray(args), env)
+	})
+}
+
 func MakeRestrictedPrimitiveFunction(name string, argCount string, function func(*Data, *SymbolTableFrame) (*Data, error)) {
 	f := &PrimitiveFunction{Name: name, Special: false, Body: function, IsRestricted: true}
 	f.parseNumArgs(argCount)
@@ -145,6 +235,41 @@ func (self *PrimitiveFunction) checkArgumentCount(argCount int) bool {
 	return false
 }
 
+func (self *PrimitiveFunction) checkArgumentTypes(argArray []*Data) (ok bool, index int, mask uint32) {
+	if len(self.ArgTypes) == 0 {
+		return true, 0, 0
+	}
+	for i, arg := range argArray {
+		mask = self.ArgTypes[len(self.ArgTypes)-1]
+		if i < len(self.ArgTypes) {
+			mask = self.ArgTypes[i]
+		}
+		if TypeMaskOf(arg)&mask == 0 {
+			return false, i, mask
+		}
+	}
+	return true, 0, 0
+}
+
+// TypeSpec describes a typed primitive's signature as a list of its argument
+// type names, the symbol ->, and its return type name, e.g.
+// ("Integer or Float" -> "Integer or Float"). It is nil for untyped primitives.
+func (self *PrimitiveFunction) TypeSpec() *Data {
+	if len(self.ArgTypes) == 0 && self.ReturnType == 0 {
+		return nil
+	}
+	spec := make([]*Data, 0, len(self.ArgTypes)+2)
+	for _, mask := range self.ArgTypes {
+		spec = append(spec, StringWithValue(TypeMaskName(mask)))
+	}
+	retType := self.ReturnType
+	if retType == 0 {
+		retType = AnyTypeMask
+	}
+	spec = append(spec, Intern("->"), StringWithValue(TypeMaskName(retType)))
+	return ArrayToList(spec)
+}
+
 func (self *PrimitiveFunction) Apply(args *Data, env *SymbolTableFrame) (result *Data, err error) {
 	if self.IsRestricted && env.IsRestricted {
 		err = fmt.Errorf("The %s primitive is restricted from execution in this environment\n", self.Name)
@@ -171,6 +296,11 @@ func (self *PrimitiveFunction) Apply(args *Data, env *SymbolTableFrame) (result
 		argArray = append(argArray, argValue)
 	}
 
+	if ok, i, mask := self.checkArgumentTypes(argArray); !ok {
+		err = ProcessError(fmt.Sprintf("%s requires argument %d to be %s but was given %s.", self.Name, i+1, TypeMaskName(mask), String(argArray[i])), env)
+		return
+	}
+
 	localGuid := atomic.AddInt64(&ProfileGUID, 1) - 1
 
 	fType := "prim"
@@ -184,6 +314,10 @@ func (self *PrimitiveFunction) Apply(args *Data, env *SymbolTableFrame) (result
 
 	ProfileExit(fType, self.Name, localGuid)
 
+	if err == nil && self.ReturnType != 0 && TypeMaskOf(result)&self.ReturnType == 0 {
+		err = ProcessError(fmt.Sprintf("%s should return %s but returned %s.", self.Name, TypeMaskName(self.ReturnType), String(result)), env)
+	}
+
 	return
 }
 
@@ -0,0 +1,206 @@
+// Copyright 2014 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file tests primitive function registration.
+
+package golisp
+
+import (
+	"fmt"
+	. "gopkg.in/check.v1"
+)
+
+type PrimitiveFunctionSuite struct {
+}
+
+var _ = Suite(&PrimitiveFunctionSuite{})
+
+func (s *PrimitiveFunctionSuite) SetUpSuite(c *C) {
+	InitLisp()
+	MakePrimitiveFunctionFull("test-double", "1", "Doubles a number.", []uint32{IntegerTypeMask | FloatTypeMask}, IntegerTypeMask|FloatTypeMask, func(args *Data, env *SymbolTableFrame) (*Data, error) {
+		if IntegerP(Car(args)) {
+			return IntegerWithValue(IntegerValue(Car(args)) * 2), nil
+		}
+		return FloatWithValue(FloatValue(Car(args)) * 2), nil
+	})
+	MakePrimitiveFunctionFull("test-bad-return", "0", "", nil, IntegerTypeMask, func(args *Data, env *SymbolTableFrame) (*Data, error) {
+		return StringWithValue("oops"), nil
+	})
+	MakeTypedPrimitiveFunction("test-describe", "2", "", "integer|string list", "string", func(args *Data, env *SymbolTableFrame) (*Data, error) {
+		return StringWithValue(String(Car(args))), nil
+	})
+	RegisterPrimitive("test-sum", "*", func(args []*Data, env *SymbolTableFrame) (*Data, error) {
+		var sum int64
+		for i, arg := range args {
+			if !IntegerP(arg) {
+				return nil, ProcessError(fmt.Sprintf("test-sum requires integers but argument %d was %s.", i+1, String(arg)), env)
+			}
+			sum += IntegerValue(arg)
+		}
+		return IntegerWithValue(sum), nil
+	})
+}
+
+func (s *PrimitiveFunctionSuite) TestDoc(c *C) {
+	code, _ := Parse("(doc test-double)")
+	result, err := Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(result), Equals, "Doubles a number.")
+}
+
+func (s *PrimitiveFunctionSuite) TestDocOfUndocumentedPrimitive(c *C) {
+	code, _ := Parse("(doc car)")
+	result, err := Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(result), Equals, "")
+}
+
+func (s *PrimitiveFunctionSuite) TestDocOfFunction(c *C) {
+	code, _ := Parse(`(define (test-documented x) "Returns x." x)`)
+	_, err := Eval(code, Global)
+	c.Assert(err, IsNil)
+
+	code, _ = Parse("(doc test-documented)")
+	result, err := Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(result), Equals, "Returns x.")
+
+	code, _ = Parse("(doc (lambda (x) x))")
+	result, err = Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(result), Equals, "")
+}
+
+func (s *PrimitiveFunctionSuite) TestDocOfNonPrimitive(c *C) {
+	code, _ := Parse("(doc 5)")
+	_, err := Eval(code, Global)
+	c.Assert(err, NotNil)
+}
+
+func (s *PrimitiveFunctionSuite) TestTypeSignature(c *C) {
+	code, _ := Parse("(type-signature test-double)")
+	result, err := Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(String(result), Equals, `("Integer or Float" -> "Integer or Float")`)
+
+	code, _ = Parse("(type-signature test-bad-return)")
+	result, err = Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(String(result), Equals, `(-> "Integer")`)
+}
+
+func (s *PrimitiveFunctionSuite) TestTypeSignatureOfUntypedFunctions(c *C) {
+	code, _ := Parse("(type-signature car)")
+	result, err := Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(NilP(result), Equals, true)
+
+	code, _ = Parse("(type-signature (lambda (x) x))")
+	result, err = Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(NilP(result), Equals, true)
+
+	code, _ = Parse("(type-signature 5)")
+	_, err = Eval(code, Global)
+	c.Assert(err, NotNil)
+}
+
+func (s *PrimitiveFunctionSuite) TestTypedArguments(c *C) {
+	code, _ := Parse("(test-double 4)")
+	result, err := Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(IntegerValue(result), Equals, int64(8))
+
+	code, _ = Parse("(test-double 1.5)")
+	result, err = Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(FloatValue(result), Equals, float32(3.0))
+}
+
+func (s *PrimitiveFunctionSuite) TestWrongArgumentType(c *C) {
+	code, _ := Parse(`(test-double "4")`)
+	_, err := Eval(code, Global)
+	c.Assert(err, NotNil)
+	c.Assert(err, ErrorMatches, `(?s).*test-double requires argument 1 to be Integer or Float but was given "4"\.`)
+}
+
+func (s *PrimitiveFunctionSuite) TestWrongReturnType(c *C) {
+	code, _ := Parse("(test-bad-return)")
+	_, err := Eval(code, Global)
+	c.Assert(err, NotNil)
+	c.Assert(err, ErrorMatches, `(?s).*test-bad-return should return Integer but returned "oops"\.`)
+}
+
+func (s *PrimitiveFunctionSuite) TestUnionTypedArgument(c *C) {
+	code, _ := Parse("(test-describe 4 '(a))")
+	result, err := Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(result), Equals, "4")
+
+	code, _ = Parse(`(test-describe "x" '(a))`)
+	result, err = Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(result), Equals, `"x"`)
+
+	code, _ = Parse("(test-describe 1.5 '(a))")
+	_, err = Eval(code, Global)
+	c.Assert(err, ErrorMatches, `(?s).*test-describe requires argument 1 to be Integer or String but was given 1.5\.`)
+}
+
+func (s *PrimitiveFunctionSuite) TestParseTypeMask(c *C) {
+	mask, err := ParseTypeMask("integer|float")
+	c.Assert(err, IsNil)
+	c.Assert(mask, Equals, uint32(IntegerTypeMask|FloatTypeMask))
+
+	mask, err = ParseTypeMask("association-list")
+	c.Assert(err, IsNil)
+	c.Assert(mask, Equals, uint32(AlistTypeMask))
+
+	masks, err := ParseArgTypes("integer|float  string anything")
+	c.Assert(err, IsNil)
+	c.Assert(masks, DeepEquals, []uint32{IntegerTypeMask | FloatTypeMask, StringTypeMask, AnyTypeMask})
+
+	_, err = ParseTypeMask("1|2")
+	c.Assert(err, ErrorMatches, "1 in the type 1\\|2 is a number of arguments.*")
+
+	_, err = ParseTypeMask("integer|widget")
+	c.Assert(err, ErrorMatches, "widget in the type integer\\|widget is not a type name")
+}
+
+func (s *PrimitiveFunctionSuite) TestSlicePrimitive(c *C) {
+	code, _ := Parse("(test-sum 1 2 (+ 1 2) 4)")
+	result, err := Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(IntegerValue(result), Equals, int64(10))
+}
+
+func (s *PrimitiveFunctionSuite) TestSlicePrimitiveWithNoArgs(c *C) {
+	code, _ := Parse("(test-sum)")
+	result, err := Eval(code, Global)
+	c.Assert(err, IsNil)
+	c.Assert(IntegerValue(result), Equals, int64(0))
+}
+
+func (s *PrimitiveFunctionSuite) TestSlicePrimitiveError(c *C) {
+	code, _ := Parse("(test-sum 1 'a)")
+	_, err := Eval(code, Global)
+	c.Assert(err, ErrorMatches, `(?s).*test-sum requires integers but argument 2 was a\.`)
+}
+
+func (s *PrimitiveFunctionSuite) TestQuoteAll(c *C) {
+	quoted := QuoteAll(InternalMakeList(Intern("a"), IntegerWithValue(1)))
+	c.Assert(String(quoted), Equals, "('a '1)")
+	c.Assert(NilP(QuoteAll(nil)), Equals, true)
+	c.Assert(NilP(QuoteAll(EmptyCons())), Equals, true)
+}
+
+func (s *PrimitiveFunctionSuite) TestApplyWithoutEvalToNoArguments(c *C) {
+	result, err := ApplyWithoutEval(Global.ValueOf(Intern("test-sum")), nil, Global)
+	c.Assert(err, IsNil)
+	c.Assert(IntegerValue(result), Equals, int64(0))
+
+	_, err = ApplyWithoutEval(Global.ValueOf(Intern("test-double")), nil, Global)
+	c.Assert(err, NotNil)
+}
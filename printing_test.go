@@ -52,6 +52,22 @@ func (s *PrintingSuite) TestNil(c *C) {
 	c.Assert(String(sexpr), Equals, "()")
 }
 
+func (s *PrintingSuite) TestEmptyAlist(c *C) {
+	sexpr := &Data{Type: AlistType, Value: nil}
+	c.Assert(String(sexpr), Equals, "()")
+}
+
+func (s *PrintingSuite) TestFalseIsNotTheEmptyList(c *C) {
+	c.Assert(String(LispFalse), Equals, "#f")
+	c.Assert(IsEqual(LispFalse, EmptyCons()), Equals, false)
+	c.Assert(IsEqual(LispFalse, nil), Equals, false)
+}
+
+func (s *PrintingSuite) TestEmptyListInsideList(c *C) {
+	sexpr := Cons(EmptyCons(), Cons(LispFalse, nil))
+	c.Assert(String(sexpr), Equals, "(() #f)")
+}
+
 func (s *PrintingSuite) TestList(c *C) {
 	sexpr := Cons(IntegerWithValue(1), Cons(StringWithValue("two"), Cons(IntegerWithValue(3), Cons(LispTrue, nil))))
 	c.Assert(String(sexpr), Equals, `(1 "two" 3 #t)`)
@@ -8,8 +8,13 @@
 package golisp
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,8 +22,24 @@ var profileOutput *os.File = nil
 var ProfileEnabled = false
 var ProfileGUID int64 = 0
 
+// Whether events are also logged as they happen, as the profile form does.
+var profileLogging = false
+
+// Every event is also kept until ResetProfile is called so the accumulated
+// profile can be written out afterwards.
+type ProfileEvent struct {
+	Time int64  `json:"time"`
+	Guid int64  `json:"guid"`
+	Mode string `json:"mode"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+var profileEvents []ProfileEvent
+var profileEventsMutex sync.Mutex
+
 func StartProfiling(fname string) {
-	ProfileGUID = 0
+	atomic.StoreInt64(&ProfileGUID, 0)
 	if fname == "" {
 		profileOutput = nil
 	} else {
@@ -28,34 +49,210 @@ func StartProfiling(fname string) {
 			panic(fmt.Sprintf("Profiler: %s could not be opened.", fname))
 		}
 	}
+	profileLogging = true
 	ProfileEnabled = true
 }
 
 func EndProfiling() {
 	ProfileEnabled = false
+	profileLogging = false
 	if profileOutput != nil {
 		profileOutput.Close()
+		profileOutput = nil
 	}
 }
 
-func ProfileEnter(funcType string, name string, guid int64) {
-	if ProfileEnabled {
-		msg := fmt.Sprintf("{time: %d guid: %d mode: 'enter type: '%s name: '%s}\n", time.Now().UnixNano(), guid, funcType, name)
+// EnableProfiling starts recording events for profile-dump without logging
+// them. When profiling is disabled, as it is by default, ProfileEnter and
+// ProfileExit return after checking ProfileEnabled.
+func EnableProfiling() {
+	ProfileEnabled = true
+}
+
+func DisableProfiling() {
+	ProfileEnabled = false
+}
+
+func recordProfileEvent(mode string, funcType string, name string, guid int64) {
+	event := ProfileEvent{Time: time.Now().UnixNano(), Guid: guid, Mode: mode, Type: funcType, Name: name}
+	if profileLogging {
+		msg := fmt.Sprintf("{time: %d guid: %d mode: '%s type: '%s name: '%s}\n", event.Time, guid, mode, funcType, name)
 		if profileOutput == nil {
-			fmt.Printf(msg)
+			fmt.Fprint(currentOutput(), msg)
 		} else {
-			fmt.Fprintf(profileOutput, msg)
+			fmt.Fprint(profileOutput, msg)
 		}
 	}
+
+	profileEventsMutex.Lock()
+	profileEvents = append(profileEvents, event)
+	profileEventsMutex.Unlock()
+}
+
+func ProfileEnter(funcType string, name string, guid int64) {
+	if ProfileEnabled {
+		recordProfileEvent("enter", funcType, name, guid)
+	}
 }
 
 func ProfileExit(funcType string, name string, guid int64) {
 	if ProfileEnabled {
-		msg := fmt.Sprintf("{time: %d guid: %d mode: 'exit type: '%s name: '%s}\n", time.Now().UnixNano(), guid, funcType, name)
-		if profileOutput == nil {
-			fmt.Printf(msg)
-		} else {
-			fmt.Fprintf(profileOutput, msg)
+		recordProfileEvent("exit", funcType, name, guid)
+	}
+}
+
+// ResetProfile discards the recorded events and restarts the guids from 0.
+func ResetProfile() {
+	profileEventsMutex.Lock()
+	profileEvents = nil
+	atomic.StoreInt64(&ProfileGUID, 0)
+	profileEventsMutex.Unlock()
+}
+
+// ProfileEvents returns a copy of the events recorded since the last reset.
+func ProfileEvents() []ProfileEvent {
+	profileEventsMutex.Lock()
+	events := make([]ProfileEvent, len(profileEvents))
+	copy(events, profileEvents)
+	profileEventsMutex.Unlock()
+	return events
+}
+
+// WriteProfileJson writes the recorded events as a JSON array.
+func WriteProfileJson(w io.Writer, events []ProfileEvent) error {
+	return json.NewEncoder(w).Encode(events)
+}
+
+type chromeTraceEvent struct {
+	Name      string  `json:"name"`
+	Category  string  `json:"cat"`
+	Phase     string  `json:"ph"`
+	Timestamp float64 `json:"ts"`
+	Pid       int     `json:"pid"`
+	Tid       int     `json:"tid"`
+}
+
+// WriteProfileChromeTrace writes the recorded events in the Chrome trace event
+// format, as begin/end pairs, for viewing in chrome://tracing.
+func WriteProfileChromeTrace(w io.Writer, events []ProfileEvent) error {
+	trace := make([]chromeTraceEvent, 0, len(events))
+	for _, event := range events {
+		phase := "B"
+		if event.Mode == "exit" {
+			phase = "E"
+		}
+		trace = append(trace, chromeTraceEvent{Name: event.Name, Category: event.Type, Phase: phase, Timestamp: float64(event.Time) / 1000.0, Pid: 1, Tid: 1})
+	}
+	return json.NewEncoder(w).Encode(map[string]interface{}{"traceEvents": trace})
+}
+
+// A ProfileCall is one application in the call tree rebuilt from the events,
+// with its start time and duration in nanoseconds. A call that hadn't
+// exited when the snapshot was taken runs until the last recorded event.
+type ProfileCall struct {
+	Guid     int64          `json:"guid"`
+	Type     string         `json:"type"`
+	Name     string         `json:"name"`
+	Start    int64          `json:"start"`
+	Duration int64          `json:"duration"`
+	Calls    []*ProfileCall `json:"calls,omitempty"`
+}
+
+// A ProfileFunction totals the calls to one function. Cumulative time only
+// counts the outermost of recursive calls, so it never exceeds the wall
+// clock time; self time excludes the time spent in the calls it made.
+type ProfileFunction struct {
+	Type           string `json:"type"`
+	Name           string `json:"name"`
+	Calls          int    `json:"calls"`
+	CumulativeTime int64  `json:"cumulativeTime"`
+	SelfTime       int64  `json:"selfTime"`
+}
+
+type ProfileSummary struct {
+	Functions []*ProfileFunction `json:"functions"`
+	CallTree  []*ProfileCall     `json:"callTree"`
+}
+
+// SummarizeProfile rebuilds the call tree from enter/exit events, matching
+// them by guid, and totals the calls and times of each function. Functions
+// are sorted by decreasing cumulative time.
+func SummarizeProfile(events []ProfileEvent) *ProfileSummary {
+	summary := &ProfileSummary{Functions: make([]*ProfileFunction, 0), CallTree: make([]*ProfileCall, 0)}
+	functions := make(map[string]*ProfileFunction)
+	var stack []*ProfileCall
+
+	functionFor := func(call *ProfileCall) *ProfileFunction {
+		key := call.Type + ":" + call.Name
+		f, found := functions[key]
+		if !found {
+			f = &ProfileFunction{Type: call.Type, Name: call.Name}
+			functions[key] = f
+			summary.Functions = append(summary.Functions, f)
 		}
+		return f
 	}
+
+	finish := func(call *ProfileCall, end int64) {
+		call.Duration = end - call.Start
+		self := call.Duration
+		for _, child := range call.Calls {
+			self -= child.Duration
+		}
+		f := functionFor(call)
+		f.SelfTime += self
+		for _, outer := range stack {
+			if outer.Type == call.Type && outer.Name == call.Name {
+				return
+			}
+		}
+		f.CumulativeTime += call.Duration
+	}
+
+	for _, event := range events {
+		if event.Mode == "enter" {
+			call := &ProfileCall{Guid: event.Guid, Type: event.Type, Name: event.Name, Start: event.Time}
+			if len(stack) == 0 {
+				summary.CallTree = append(summary.CallTree, call)
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Calls = append(parent.Calls, call)
+			}
+			stack = append(stack, call)
+			functionFor(call).Calls++
+			continue
+		}
+		// Pop to the matching call; anything above it never recorded its exit.
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].Guid == event.Guid {
+				for len(stack) > i {
+					call := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					finish(call, event.Time)
+				}
+				break
+			}
+		}
+	}
+
+	if len(events) > 0 {
+		last := events[len(events)-1].Time
+		for len(stack) > 0 {
+			call := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			finish(call, last)
+		}
+	}
+
+	sort.SliceStable(summary.Functions, func(i, j int) bool {
+		return summary.Functions[i].CumulativeTime > summary.Functions[j].CumulativeTime
+	})
+	return summary
+}
+
+// DumpProfileJSON writes a summary of the events recorded so far, as made
+// by SummarizeProfile, as JSON. It works from a snapshot, so it is safe to
+// call while profiling continues.
+func DumpProfileJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(SummarizeProfile(ProfileEvents()))
 }
@@ -0,0 +1,186 @@
+// Copyright 2015 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file tests the profiler support.
+
+package golisp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	. "gopkg.in/check.v1"
+	"io/ioutil"
+	"path/filepath"
+	"sync/atomic"
+)
+
+type ProfilingSuite struct {
+	dir string
+}
+
+var _ = Suite(&ProfilingSuite{})
+
+func (s *ProfilingSuite) SetUpSuite(c *C) {
+	InitLisp()
+	ParseAndEval("(define (profiling-fact n) (if (< n 2) 1 (* n (profiling-fact (- n 1)))))")
+}
+
+func (s *ProfilingSuite) SetUpTest(c *C) {
+	s.dir = c.MkDir()
+	ParseAndEval("(profile-reset)")
+	_, err := ParseAndEval(fmt.Sprintf("(profile (profiling-fact 3) %q)", filepath.Join(s.dir, "profile.log")))
+	c.Assert(err, IsNil)
+}
+
+func (s *ProfilingSuite) TestDumpJson(c *C) {
+	path := filepath.Join(s.dir, "profile.json")
+	count, err := ParseAndEval(fmt.Sprintf("(profile-dump %q 'json)", path))
+	c.Assert(err, IsNil)
+	c.Assert(IntegerValue(count) > 0, Equals, true)
+
+	contents, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	var events []ProfileEvent
+	c.Assert(json.Unmarshal(contents, &events), IsNil)
+	c.Assert(len(events), Equals, int(IntegerValue(count)))
+
+	entered := 0
+	for _, event := range events {
+		if event.Name == "profiling-fact" && event.Mode == "enter" {
+			entered++
+		}
+	}
+	c.Assert(entered, Equals, 3)
+}
+
+func (s *ProfilingSuite) TestDumpChromeTrace(c *C) {
+	path := filepath.Join(s.dir, "profile.trace")
+	count, err := ParseAndEval(fmt.Sprintf("(profile-dump %q 'chrome-trace)", path))
+	c.Assert(err, IsNil)
+
+	contents, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	var trace struct {
+		TraceEvents []map[string]interface{} `json:"traceEvents"`
+	}
+	c.Assert(json.Unmarshal(contents, &trace), IsNil)
+	c.Assert(len(trace.TraceEvents), Equals, int(IntegerValue(count)))
+	c.Assert(trace.TraceEvents[0]["ph"], Equals, "B")
+}
+
+func (s *ProfilingSuite) TestReset(c *C) {
+	ParseAndEval("(profile-reset)")
+	count, err := ParseAndEval(fmt.Sprintf("(profile-dump %q 'json)", filepath.Join(s.dir, "empty.json")))
+	c.Assert(err, IsNil)
+	c.Assert(IntegerValue(count), Equals, int64(0))
+}
+
+func (s *ProfilingSuite) TestDumpRejectsUnknownFormat(c *C) {
+	_, err := ParseAndEval(fmt.Sprintf("(profile-dump %q 'xml)", filepath.Join(s.dir, "profile.xml")))
+	c.Assert(err, ErrorMatches, "(?s).*format of json or chrome-trace.*")
+}
+
+func (s *ProfilingSuite) TestDumpSummary(c *C) {
+	path := filepath.Join(s.dir, "summary.json")
+	_, err := ParseAndEval(fmt.Sprintf("(profile-dump %q)", path))
+	c.Assert(err, IsNil)
+
+	contents, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	var summary ProfileSummary
+	c.Assert(json.Unmarshal(contents, &summary), IsNil)
+
+	var fact *ProfileFunction
+	for _, f := range summary.Functions {
+		if f.Name == "profiling-fact" {
+			fact = f
+		}
+	}
+	c.Assert(fact, NotNil)
+	c.Assert(fact.Calls, Equals, 3)
+	c.Assert(fact.SelfTime <= fact.CumulativeTime, Equals, true)
+
+	// The recursive calls nest in the call tree.
+	depth := 0
+	var find func(calls []*ProfileCall, nested int)
+	find = func(calls []*ProfileCall, nested int) {
+		for _, call := range calls {
+			if call.Name == "profiling-fact" {
+				if nested+1 > depth {
+					depth = nested + 1
+				}
+				find(call.Calls, nested+1)
+			} else {
+				find(call.Calls, nested)
+			}
+		}
+	}
+	find(summary.CallTree, 0)
+	c.Assert(depth, Equals, 3)
+}
+
+func (s *ProfilingSuite) TestSummarizeProfile(c *C) {
+	events := []ProfileEvent{
+		{Time: 0, Guid: 1, Mode: "enter", Type: "func", Name: "outer"},
+		{Time: 10, Guid: 2, Mode: "enter", Type: "prim", Name: "inner"},
+		{Time: 30, Guid: 2, Mode: "exit", Type: "prim", Name: "inner"},
+		{Time: 40, Guid: 3, Mode: "enter", Type: "func", Name: "outer"},
+		{Time: 45, Guid: 3, Mode: "exit", Type: "func", Name: "outer"},
+		{Time: 50, Guid: 1, Mode: "exit", Type: "func", Name: "outer"},
+		{Time: 60, Guid: 4, Mode: "enter", Type: "prim", Name: "inner"},
+		{Time: 65, Guid: 5, Mode: "enter", Type: "prim", Name: "unfinished"},
+	}
+	summary := SummarizeProfile(events)
+
+	c.Assert(len(summary.Functions), Equals, 3)
+	outer := summary.Functions[0]
+	c.Assert(outer.Name, Equals, "outer")
+	c.Assert(outer.Calls, Equals, 2)
+	c.Assert(outer.CumulativeTime, Equals, int64(50))
+	c.Assert(outer.SelfTime, Equals, int64(30))
+
+	inner := summary.Functions[1]
+	c.Assert(inner.Name, Equals, "inner")
+	c.Assert(inner.Calls, Equals, 2)
+	c.Assert(inner.CumulativeTime, Equals, int64(25))
+
+	c.Assert(len(summary.CallTree), Equals, 2)
+	c.Assert(len(summary.CallTree[0].Calls), Equals, 2)
+	c.Assert(summary.CallTree[1].Calls[0].Name, Equals, "unfinished")
+	c.Assert(summary.CallTree[1].Calls[0].Duration, Equals, int64(0))
+}
+
+func (s *ProfilingSuite) TestDumpProfileJSONWhileProfiling(c *C) {
+	ParseAndEval("(profile-reset)")
+	StartProfiling(filepath.Join(s.dir, "running.log"))
+	defer EndProfiling()
+	ProfileEnter("func", "running", 1)
+
+	var buf bytes.Buffer
+	c.Assert(DumpProfileJSON(&buf), IsNil)
+	var summary ProfileSummary
+	c.Assert(json.Unmarshal(buf.Bytes(), &summary), IsNil)
+	c.Assert(len(summary.CallTree), Equals, 1)
+	c.Assert(summary.CallTree[0].Name, Equals, "running")
+}
+
+func (s *ProfilingSuite) TestEnableAndDisable(c *C) {
+	ParseAndEval("(reset-profile)")
+	c.Assert(atomic.LoadInt64(&ProfileGUID), Equals, int64(0))
+
+	_, err := ParseAndEval("(profile-enable)")
+	c.Assert(err, IsNil)
+	c.Assert(ProfileEnabled, Equals, true)
+	ParseAndEval("(profiling-fact 2)")
+	_, err = ParseAndEval("(profile-disable)")
+	c.Assert(err, IsNil)
+	c.Assert(ProfileEnabled, Equals, false)
+
+	recorded := len(ProfileEvents())
+	c.Assert(recorded > 0, Equals, true)
+	ParseAndEval("(profiling-fact 2)")
+	c.Assert(len(ProfileEvents()), Equals, recorded)
+}
@@ -9,53 +9,207 @@ package golisp
 
 import (
 	"container/list"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 )
 
+// ErrIncompleteInput is returned by ReplStep when the input so far doesn't
+// make up a complete expression and the next line should continue it.
+var ErrIncompleteInput = errors.New("Incomplete input")
+
+// InputNeedsMore reports whether input is the start of an expression that
+// continues on another line: it has unclosed parentheses, brackets, or braces,
+// an unterminated string, or ends with a quote. Delimiters inside strings and
+// comments are ignored. It's an error for input to close more than it opens,
+// since no amount of further input can balance it.
+func InputNeedsMore(input string) (more bool, err error) {
+	depth := 0
+	inString := false
+	inComment := false
+	escaped := false
+	pendingQuote := false
+	for _, ch := range input {
+		switch {
+		case inComment:
+			if ch == '\n' {
+				inComment = false
+			}
+		case inString:
+			if escaped {
+				escaped = false
+			} else if ch == '\\' {
+				escaped = true
+			} else if ch == '"' {
+				inString = false
+			}
+		case ch == ';':
+			inComment = true
+		case ch == '"':
+			inString = true
+			pendingQuote = false
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+			pendingQuote = false
+		case ch == ')' || ch == ']' || ch == '}':
+			depth--
+			if depth < 0 {
+				return false, errors.New(fmt.Sprintf("Unexpected '%c'", ch))
+			}
+			pendingQuote = false
+		case ch == '\'' || ch == '`' || ch == ',' || ch == '@':
+			pendingQuote = true
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+		default:
+			pendingQuote = false
+		}
+	}
+	return depth > 0 || inString || pendingQuote, nil
+}
+
+// A ReplSession evaluates input a step at a time for embedders that run
+// their own console rather than Repl. History holds each complete input that
+// parsed, without repeating the previous one; callers may read, save, or
+// replace it. If Output is set, what the session evaluates prints there rather
+// than to the interpreter's Output; other sessions and goroutines are
+// unaffected.
+type ReplSession struct {
+	Env     *SymbolTableFrame
+	History []string
+	Output  io.Writer
+	buffer  string
+}
+
+func NewReplSession() *ReplSession {
+	return &ReplSession{Env: NewSymbolTableFrameBelow(Global, "Repl")}
+}
+
+// Pending returns the input buffered so far for an incomplete expression.
+func (self *ReplSession) Pending() string {
+	return self.buffer
+}
+
+// Reset discards any buffered input.
+func (self *ReplSession) Reset() {
+	self.buffer = ""
+}
+
+// ReplStep adds a line of input. Once it completes an expression that is
+// parsed and evaluated, returning the printed result. Until then it returns
+// ErrIncompleteInput and buffers the input. Any other error discards the
+// buffered input.
+func (self *ReplSession) ReplStep(input string) (output string, err error) {
+	code, parsed, err := self.read(input)
+	if err != nil || !parsed {
+		return
+	}
+	return self.eval(code)
+}
+
+// Adds a line of input, returning the expression once it is complete. parsed
+// is false if the complete input was blank.
+func (self *ReplSession) read(input string) (code *Data, parsed bool, err error) {
+	if self.buffer == "" {
+		self.buffer = input
+	} else {
+		self.buffer = self.buffer + "\n" + input
+	}
+	source := self.buffer
+
+	more, err := InputNeedsMore(source)
+	if err != nil {
+		self.Reset()
+		return
+	}
+	if more {
+		err = ErrIncompleteInput
+		return
+	}
+	self.Reset()
+
+	if strings.TrimSpace(source) == "" {
+		return
+	}
+	code, err = Parse(source)
+	if err != nil {
+		return
+	}
+	if len(self.History) == 0 || self.History[len(self.History)-1] != source {
+		self.History = append(self.History, source)
+	}
+	return code, true, nil
+}
+
+func (self *ReplSession) eval(code *Data) (output string, err error) {
+	self.Env.Output = self.Output
+	self.Env.CurrentCode = list.New()
+	d, err := Eval(code, self.Env)
+	if err != nil {
+		return
+	}
+	return String(d), nil
+}
+
+var defaultReplSession *ReplSession
+
+// ReplStep steps a session shared by all callers, created on first use.
+func ReplStep(input string) (output string, err error) {
+	if defaultReplSession == nil {
+		defaultReplSession = NewReplSession()
+	}
+	return defaultReplSession.ReplStep(input)
+}
+
 func Repl() {
 	IsInteractive = true
-	fmt.Printf("Welcome to GoLisp 1.0\n")
-	fmt.Printf("Copyright 2015 SteelSeries\n")
-	fmt.Printf("Evaluate '(quit)' to exit.\n\n")
-	prompt := "> "
+	fmt.Fprintf(currentOutput(), "Welcome to GoLisp 1.0\n")
+	fmt.Fprintf(currentOutput(), "Copyright 2015 SteelSeries\n")
+	fmt.Fprintf(currentOutput(), "Evaluate '(quit)' to exit.\n\n")
+	readyPrompt := "> "
+	continuationPrompt := "... "
+	prompt := readyPrompt
 	LoadHistoryFromFile(".golisp_history")
-	lastInput := ""
-	replEnv := NewSymbolTableFrameBelow(Global, "Repl")
+	session := NewReplSession()
 	for true {
 		defer func() {
 			if x := recover(); x != nil {
-				fmt.Printf("Don't Panic! %v\n", x)
+				fmt.Fprintf(currentOutput(), "Don't Panic! %v\n", x)
 			}
 		}()
 		DebugCurrentFrame = nil
 		DebugSingleStep = false
 		DebugEvalInDebugRepl = false
-		replEnv.CurrentCode = list.New()
 		inputp := ReadLine(&prompt)
 		if inputp == nil {
 			QuitImpl(nil, nil)
 		} else {
-			input := *inputp
-			//			fmt.Printf("input: <%s>\n", inputp)
-			if input != "" {
-				code, err := Parse(input)
-				if err != nil {
-					fmt.Printf("Error: %s\n", err)
-				} else {
-					if input != lastInput {
-						AddHistory(input)
-						lastInput = input
-					}
-					d, err := Eval(code, replEnv)
-					if err != nil {
-						fmt.Printf("Error in evaluation: %s\n", err)
-						if DebugOnError {
-							DebugRepl(DebugErrorEnv)
-						}
-					} else {
-						fmt.Printf("==> %s\n", String(d))
-					}
+			historyLength := len(session.History)
+			code, parsed, err := session.read(*inputp)
+			if err == ErrIncompleteInput {
+				prompt = continuationPrompt
+				continue
+			}
+			prompt = readyPrompt
+			if err != nil {
+				fmt.Fprintf(currentOutput(), "Error: %s\n", err)
+				continue
+			}
+			if !parsed {
+				continue
+			}
+			if len(session.History) > historyLength {
+				AddHistory(session.History[len(session.History)-1])
+			}
+
+			output, err := session.eval(code)
+			if err != nil {
+				fmt.Fprintf(currentOutput(), "Error in evaluation: %s\n", err)
+				if DebugOnError {
+					DebugRepl(DebugErrorEnv)
 				}
+			} else {
+				fmt.Fprintf(currentOutput(), "==> %s\n", output)
 			}
 		}
 	}
@@ -0,0 +1,193 @@
+// Copyright 2014 SteelSeries ApS.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This package implements a basic LISP interpretor for embedding in a go program for scripting.
+// This file tests the step-at-a-time REPL support.
+
+package golisp
+
+import (
+	"bytes"
+	"fmt"
+	. "gopkg.in/check.v1"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+type ReplSuite struct {
+	session *ReplSession
+}
+
+var _ = Suite(&ReplSuite{})
+
+func (s *ReplSuite) SetUpSuite(c *C) {
+	InitLisp()
+}
+
+func (s *ReplSuite) SetUpTest(c *C) {
+	s.session = NewReplSession()
+}
+
+func (s *ReplSuite) TestInputNeedsMore(c *C) {
+	for _, input := range []string{"(+ 1", "(define (f x)\n  (+ x", "\"abc", "'", "{a: 1", "[1 2", "(str \")\"", "(+ 1 ; 2)"} {
+		more, err := InputNeedsMore(input)
+		c.Assert(err, IsNil)
+		c.Assert(more, Equals, true, Commentf("input: %q", input))
+	}
+	for _, input := range []string{"", "5", "(+ 1 2)", "\"(\"", "'a", "(+ 1 2) ; (", "(str \"\\\"(\")"} {
+		more, err := InputNeedsMore(input)
+		c.Assert(err, IsNil)
+		c.Assert(more, Equals, false, Commentf("input: %q", input))
+	}
+	_, err := InputNeedsMore("(+ 1 2))")
+	c.Assert(err, NotNil)
+}
+
+func (s *ReplSuite) TestSteps(c *C) {
+	output, err := s.session.ReplStep("(define repl-x 5)")
+	c.Assert(err, IsNil)
+	c.Assert(output, Equals, "5")
+	output, err = s.session.ReplStep("(* repl-x 2)")
+	c.Assert(err, IsNil)
+	c.Assert(output, Equals, "10")
+	output, err = s.session.ReplStep("")
+	c.Assert(err, IsNil)
+	c.Assert(output, Equals, "")
+}
+
+func (s *ReplSuite) TestContinuation(c *C) {
+	_, err := s.session.ReplStep("(define (repl-add a b)")
+	c.Assert(err, Equals, ErrIncompleteInput)
+	_, err = s.session.ReplStep("  (+ a")
+	c.Assert(err, Equals, ErrIncompleteInput)
+	c.Assert(s.session.Pending(), Equals, "(define (repl-add a b)\n  (+ a")
+	_, err = s.session.ReplStep("     b))")
+	c.Assert(err, IsNil)
+	c.Assert(s.session.Pending(), Equals, "")
+	output, err := s.session.ReplStep("(repl-add 3 4)")
+	c.Assert(err, IsNil)
+	c.Assert(output, Equals, "7")
+}
+
+func (s *ReplSuite) TestUnbalancedInputResets(c *C) {
+	_, err := s.session.ReplStep("(+ 1")
+	c.Assert(err, Equals, ErrIncompleteInput)
+	_, err = s.session.ReplStep("2)))")
+	c.Assert(err, NotNil)
+	c.Assert(err, Not(Equals), ErrIncompleteInput)
+	c.Assert(s.session.Pending(), Equals, "")
+	output, err := s.session.ReplStep("(+ 1 2)")
+	c.Assert(err, IsNil)
+	c.Assert(output, Equals, "3")
+}
+
+func (s *ReplSuite) TestEvaluationErrors(c *C) {
+	_, err := s.session.ReplStep("(car 1 2 3)")
+	c.Assert(err, NotNil)
+	c.Assert(err, Not(Equals), ErrIncompleteInput)
+}
+
+func (s *ReplSuite) TestHistory(c *C) {
+	s.session.ReplStep("(+ 1 2)")
+	s.session.ReplStep("(+ 1 2)")
+	s.session.ReplStep("(list 1")
+	s.session.ReplStep("2)")
+	c.Assert(s.session.History, DeepEquals, []string{"(+ 1 2)", "(list 1\n2)"})
+}
+
+func (s *ReplSuite) TestSetOutput(c *C) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(nil)
+
+	_, err := ParseAndEval(`(write-line "captured " 42)`)
+	c.Assert(err, IsNil)
+	BindingWithSymbolAndValue(Intern("x"), IntegerWithValue(1)).Dump()
+	c.Assert(buf.String(), Equals, "captured 42\n   x => 1\n")
+
+	SetOutput(nil)
+	c.Assert(Output, Equals, os.Stdout)
+}
+
+func (s *ReplSuite) TestSessionOutput(c *C) {
+	var buf bytes.Buffer
+	s.session.Output = &buf
+	_, err := s.session.ReplStep(`(write-string "from the session")`)
+	c.Assert(err, IsNil)
+	c.Assert(buf.String(), Equals, "from the session")
+	c.Assert(Output, Equals, os.Stdout)
+}
+
+func (s *ReplSuite) TestSessionsKeepTheirOwnOutput(c *C) {
+	var bufA, bufB bytes.Buffer
+	s.session.Output = &bufA
+	other := NewReplSession()
+	other.Output = &bufB
+
+	var wg sync.WaitGroup
+	writeFrom := func(session *ReplSession, text string) {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			session.ReplStep(fmt.Sprintf(`(write-string "%s")`, text))
+		}
+	}
+	wg.Add(2)
+	go writeFrom(s.session, "a")
+	go writeFrom(other, "b")
+	wg.Wait()
+
+	c.Assert(bufA.String(), Equals, strings.Repeat("a", 50))
+	c.Assert(bufB.String(), Equals, strings.Repeat("b", 50))
+	c.Assert(Output, Equals, os.Stdout)
+}
+
+func (s *ReplSuite) TestSetInput(c *C) {
+	SetInput(strings.NewReader("first line\n(a b)\n"))
+	defer SetInput(nil)
+
+	line, err := ParseAndEval(`(read-line)`)
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(line), Equals, "first line")
+	form, err := ParseAndEval(`(read)`)
+	c.Assert(err, IsNil)
+	c.Assert(String(form), Equals, "(a b)")
+	eof, err := ParseAndEval(`(eof-object? (read))`)
+	c.Assert(err, IsNil)
+	c.Assert(BooleanValue(eof), Equals, true)
+
+	SetInput(nil)
+	c.Assert(Input, Equals, os.Stdin)
+}
+
+// A reader whose dynamic type can't be compared with ==.
+type uncomparableReader struct {
+	lines []string
+	read  *int
+}
+
+func (self uncomparableReader) Read(p []byte) (n int, err error) {
+	if *self.read >= len(self.lines) {
+		return 0, io.EOF
+	}
+	n = copy(p, self.lines[*self.read])
+	*self.read++
+	return
+}
+
+func (s *ReplSuite) TestSetInputWithAnUncomparableReader(c *C) {
+	SetInput(uncomparableReader{lines: []string{"one\n", "two\n"}, read: new(int)})
+	defer SetInput(nil)
+
+	line, err := ParseAndEval(`(read-line)`)
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(line), Equals, "one")
+	line, err = ParseAndEval(`(with-input-from-string "inner\n" read-line)`)
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(line), Equals, "inner")
+	line, err = ParseAndEval(`(read-line)`)
+	c.Assert(err, IsNil)
+	c.Assert(StringValue(line), Equals, "two")
+}
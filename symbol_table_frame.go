@@ -11,6 +11,7 @@ import (
 	"container/list"
 	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"sync/atomic"
 )
@@ -29,6 +30,8 @@ type SymbolTableFrame struct {
 	Mutex        sync.RWMutex
 	CurrentCode  *list.List
 	IsRestricted bool
+	CatchTag     *Data
+	Output       io.Writer
 }
 
 type symbolsTable struct {
@@ -59,6 +62,14 @@ func Intern(name string) (sym *Data) {
 	return
 }
 
+// InternedP reports whether a symbol with the given name has been interned.
+func InternedP(name string) bool {
+	internedSymbols.Mutex.RLock()
+	_, found := internedSymbols.Symbols[name]
+	internedSymbols.Mutex.RUnlock()
+	return found
+}
+
 func (self *SymbolTableFrame) Depth() int {
 	if self.Previous == nil {
 		return 1
@@ -76,7 +87,7 @@ func (self *SymbolTableFrame) CurrentCodeString() string {
 }
 
 func (self *SymbolTableFrame) InternalDump(frameNumber int) {
-	fmt.Printf("Frame %d: %s\n", frameNumber, self.CurrentCodeString())
+	fmt.Fprintf(currentOutput(), "Frame %d: %s\n", frameNumber, self.CurrentCodeString())
 	self.Mutex.RLock()
 	defer self.Mutex.RUnlock()
 	for _, b := range self.Bindings {
@@ -84,20 +95,20 @@ func (self *SymbolTableFrame) InternalDump(frameNumber int) {
 			b.Dump()
 		}
 	}
-	fmt.Printf("\n")
+	fmt.Fprintf(currentOutput(), "\n")
 	if self.Previous != nil {
 		self.Previous.InternalDump(frameNumber + 1)
 	}
 }
 
 func (self *SymbolTableFrame) Dump() {
-	println()
+	fmt.Fprintln(currentOutput())
 	self.InternalDump(0)
 }
 
 func (self *SymbolTableFrame) DumpSingleFrame(frameNumber int) {
 	if frameNumber == 0 {
-		fmt.Printf("%s\n", self.CurrentCodeString())
+		fmt.Fprintf(currentOutput(), "%s\n", self.CurrentCodeString())
 		self.Mutex.RLock()
 		defer self.Mutex.RUnlock()
 		for _, b := range self.Bindings {
@@ -105,28 +116,28 @@ func (self *SymbolTableFrame) DumpSingleFrame(frameNumber int) {
 				b.Dump()
 			}
 		}
-		fmt.Printf("\n")
+		fmt.Fprintf(currentOutput(), "\n")
 	} else if self.Previous != nil {
 		self.Previous.DumpSingleFrame(frameNumber - 1)
 	} else {
-		fmt.Printf("Invalid frame selected.\n")
+		fmt.Fprintf(currentOutput(), "Invalid frame selected.\n")
 	}
 }
 
 func (self *SymbolTableFrame) InternalDumpHeaders(frameNumber int) {
-	fmt.Printf("Frame %d: %s\n", frameNumber, self.CurrentCodeString())
+	fmt.Fprintf(currentOutput(), "Frame %d: %s\n", frameNumber, self.CurrentCodeString())
 	if self.Previous != nil {
 		self.Previous.InternalDumpHeaders(frameNumber + 1)
 	}
 }
 
 func (self *SymbolTableFrame) DumpHeaders() {
-	println()
+	fmt.Fprintln(currentOutput())
 	self.InternalDumpHeaders(0)
 }
 
 func (self *SymbolTableFrame) DumpHeader() {
-	fmt.Printf("%s\n", self.CurrentCodeString())
+	fmt.Fprintf(currentOutput(), "%s\n", self.CurrentCodeString())
 }
 
 func NewSymbolTableFrameBelow(p *SymbolTableFrame, name string) *SymbolTableFrame {
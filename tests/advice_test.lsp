@@ -0,0 +1,68 @@
+;;; -*- mode: Scheme -*-
+
+(define advice-log '())
+
+(define (note! x)
+  (set! advice-log (cons x advice-log)))
+
+(define (advised-add a b)
+  (note! 'body)
+  (+ a b))
+
+(define (log-args a b)
+  (note! (list 'before a b)))
+
+(define (log-result r)
+  (note! (list 'after r)))
+
+(define (reject-negatives a b)
+  (if (< a 0)
+      (error "negative")
+      #t))
+
+(context "function advice"
+
+         ((set! advice-log '())
+          (remove-advice 'advised-add))
+
+         (it "runs before advice with the arguments"
+             (add-advice 'advised-add 'before log-args)
+             (assert-eq (advised-add 1 2) 3)
+             (assert-eq (reverse advice-log) '((before 1 2) body)))
+
+         (it "runs after advice with the result"
+             (add-advice 'advised-add 'after log-result)
+             (assert-eq (advised-add 1 2) 3)
+             (assert-eq (reverse advice-log) '(body (after 3))))
+
+         (it "runs advice in order"
+             (add-advice 'advised-add 'after log-result)
+             (add-advice 'advised-add 'before log-args)
+             (add-advice 'advised-add 'before (lambda (a b) (note! 'second)))
+             (advised-add 1 2)
+             (assert-eq (reverse advice-log) '((before 1 2) second body (after 3))))
+
+         (it "can remove one piece of advice"
+             (add-advice 'advised-add 'before log-args)
+             (add-advice 'advised-add 'after log-result)
+             (remove-advice 'advised-add 'before log-args)
+             (advised-add 1 2)
+             (assert-eq (reverse advice-log) '(body (after 3))))
+
+         (it "can remove all advice"
+             (add-advice 'advised-add 'before log-args)
+             (add-advice 'advised-add 'after log-result)
+             (remove-advice 'advised-add)
+             (advised-add 1 2)
+             (assert-eq advice-log '(body)))
+
+         (it "aborts the call when advice errors"
+             (add-advice 'advised-add 'before reject-negatives)
+             (assert-eq (advised-add 1 2) 3)
+             (assert-error (advised-add -1 2))
+             (assert-eq advice-log '(body)))
+
+         (it "rejects bad arguments"
+             (assert-error (add-advice 5 'before log-args))
+             (assert-error (add-advice 'advised-add 'during log-args))
+             (assert-error (add-advice 'advised-add 'before 5))))
@@ -47,3 +47,57 @@
          (it "can remove"
                    (assert-eq (dissoc 'a (alist '((a . 1) (b . 2) (c . 3))))
                               (alist '((b . 2) (c . 3))))))
+
+(context "option parsing"
+
+         ()
+
+         (it "defaults everything when no options are given"
+             (assert-eq (parse-options '() '((width . 0) (pad . " ")))
+                        (alist '((width . 0) (pad . " ")))))
+
+         (it "overrides defaults with given options"
+             (assert-eq (parse-options '((pad . "0")) '((width . 0) (pad . " ")))
+                        (alist '((width . 0) (pad . "0"))))
+             (assert-eq (parse-options (alist '((pad . "*") (width . 5))) '((width . 0) (pad . " ")))
+                        (alist '((width . 5) (pad . "*")))))
+
+         (it "keeps unknown options unless strict"
+             (assert-eq (parse-options '((base . 16)) '((width . 0)))
+                        (alist '((width . 0) (base . 16))))
+             (assert-error (parse-options '((base . 16)) '((width . 0)) #t))
+             (assert-eq (parse-options '((width . 3)) '((width . 0)) #t)
+                        (alist '((width . 3)))))
+
+         (it "rejects malformed options"
+             (assert-error (parse-options 5 '((width . 0))))
+             (assert-error (parse-options '(width) '((width . 0))))
+             (assert-error (parse-options '((3 . 4)) '((width . 0))))
+             (assert-error (parse-options '() 5)))
+
+         (it "can remove keys"
+                   (define colors (alist '((r . 1) (g . 2) (b . 3))))
+                   (assert-eq (alist-remove 'g colors)
+                              (alist '((r . 1) (b . 3))))
+                   (assert-eq (alist-keys (alist-remove 'r colors)) '(g b))
+                   (assert-eq (alist-remove 'x colors) colors)
+                   (assert-eq (alist-keys colors) '(r g b))
+                   (assert-nil (alist-remove 'a '()))
+                   (assert-error (alist-remove 'a 5))
+                   (assert-error (alist-remove 'a '(a b))))
+
+         (it "can set keys"
+                   (define colors (alist '((r . 1) (g . 2))))
+                   (assert-eq (alist-values (alist-set 'g 5 colors)) '(1 5))
+                   (assert-eq (alist-keys (alist-set 'b 3 colors)) '(b r g))
+                   (assert-eq (cdr (assoc 'g colors)) 2)
+                   (assert-eq (alist-set "k" 1 '()) (alist '(("k" . 1))))
+                   (assert-error (alist-set '(1) 1 '()))
+                   (assert-error (alist-set 'a 1 5)))
+
+         (it "can list keys and values"
+                   (assert-eq (alist-keys (alist '((a . 1) (b . 2)))) '(a b))
+                   (assert-eq (alist-values (alist '((a . 1) (b . 2)))) '(1 2))
+                   (assert-eq (alist-keys '((a . 1))) '(a))
+                   (assert-nil (alist-keys '()))
+                   (assert-nil (alist-values '()))))
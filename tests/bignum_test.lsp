@@ -0,0 +1,123 @@
+;;; -*- mode: Scheme -*-
+
+(define (factorial n)
+  (if (< n 2)
+      1
+      (* n (factorial (- n 1)))))
+
+(context "big integers"
+
+         ()
+
+         (it "are made by big"
+             (assert-eq (type-of (big 5)) 'big-integer)
+             (assert-eq (big "123456789012345678901234567890")
+                        (+ (big "123456789012345678901234567889") 1))
+             (assert-error (big 1.5))
+             (assert-error (big "12x")))
+
+         (it "are integers and numbers"
+             (assert-true (integer? (big 5)))
+             (assert-true (number? (big 5)))
+             (assert-false (integer? 5.0)))
+
+         (it "come from overflowing integer arithmetic"
+             (assert-eq (type-of (+ 9223372036854775807 1)) 'big-integer)
+             (assert-eq (str (+ 9223372036854775807 1)) "9223372036854775808")
+             (assert-eq (str (- (- 0 9223372036854775807) 2)) "-9223372036854775809")
+             (assert-eq (str (* 4294967296 4294967296)) "18446744073709551616")
+             (assert-eq (type-of (* 3037000499 3037000499)) 'integer))
+
+         (it "compute factorials beyond 2^63"
+             (assert-eq (str (factorial 20)) "2432902008176640000")
+             (assert-eq (type-of (factorial 20)) 'integer)
+             (assert-eq (str (factorial 25)) "15511210043330985984000000")
+             (assert-eq (factorial 25) (big "15511210043330985984000000")))
+
+         (it "promote the rest of a calculation"
+             (assert-eq (type-of (+ (big 1) 2)) 'big-integer)
+             (assert-eq (- (big 10) 3 2) 5)
+             (assert-eq (* 2 (big 21)) 42)
+             (assert-eq (type-of (+ (big 1) 0.5)) 'float))
+
+         (it "compare by value"
+             (assert-true (== (big 5) 5))
+             (assert-true (== 5 (big 5)))
+             (assert-false (== (big 5) 6))
+             (assert-true (equal? (list (big 5)) '(5)))
+             (assert-true (< (factorial 25) (factorial 26)))
+             (assert-true (> (+ 9223372036854775807 1) 9223372036854775807))
+             (assert-false (< (+ 9223372036854775807 1) 9223372036854775807))
+             (assert-true (<= (big 3) 3))
+             (assert-true (>= (big 3) 3))
+             (assert-true (< (big 3) 3.5)))
+
+         (it "are read from literals too big for an integer"
+             (assert-eq (type-of 123456789012345678901234567890) 'big-integer)
+             (assert-eq 123456789012345678901234567890 (big "123456789012345678901234567890"))
+             (assert-eq (type-of -9223372036854775809) 'big-integer)
+             (assert-eq (type-of 9223372036854775807) 'integer)
+             (assert-eq #xFFFFFFFFFFFFFFFF 18446744073709551615)
+             (assert-eq #b10000000000000000000000000000000000000000000000000000000000000000 18446744073709551616)))
+
+(context "big integer primitives"
+
+         ()
+
+         (it "divide"
+             (assert-eq (quotient (big 10) 3) 3)
+             (assert-eq (quotient (factorial 25) (factorial 24)) 25)
+             (assert-eq (remainder (big -7) 2) -1)
+             (assert-eq (modulo (big -7) 2) 1)
+             (assert-eq (modulo 7 (big -2)) -1)
+             (assert-eq (/ (factorial 25) (factorial 23)) 600)
+             (assert-eq (str (quotient -9223372036854775808 -1)) "9223372036854775808")
+             (assert-eq (str (/ -9223372036854775808 -1)) "9223372036854775808")
+             (assert-error (quotient (big 10) 0))
+             (assert-error (modulo (big 10) (big 0))))
+
+         (it "floor divide"
+             (assert-eq (call-with-values (lambda () (floor/ (big -7) 2)) list) '(-4 1)))
+
+         (it "test parity"
+             (assert-true (even? (factorial 25)))
+             (assert-true (odd? (+ (factorial 25) 1)))
+             (assert-false (odd? (big 4))))
+
+         (it "step with succ and pred"
+             (assert-eq (succ (big 5)) 6)
+             (assert-eq (str (succ 9223372036854775807)) "9223372036854775808")
+             (assert-eq (str (pred -9223372036854775808)) "-9223372036854775809"))
+
+         (it "convert back to integers only when in range"
+             (assert-eq (type-of (integer (big 5))) 'integer)
+             (assert-eq (integer (big 5)) 5)
+             (assert-error (integer (big "123456789012345678901234567890")))
+             (assert-error (integer 1.0e30)))
+
+         (it "take absolute values and signs exactly"
+             (assert-eq (str (abs (big "-123456789012345678901234567890"))) "123456789012345678901234567890")
+             (assert-eq (str (abs -9223372036854775808)) "9223372036854775808")
+             (assert-eq (abs -9007199254740993) 9007199254740993)
+             (assert-eq (sign (big "-123456789012345678901234567890")) -1)
+             (assert-eq (sign (factorial 25)) 1))
+
+         (it "find minimums and maximums"
+             (assert-eq (max (list 1 (factorial 25) 3)) (factorial 25))
+             (assert-eq (min (list 1 (- 0 (factorial 25)) 3)) (- 0 (factorial 25))))
+
+         (it "raise to powers"
+             (assert-eq (pow 2 10) 1024)
+             (assert-eq (type-of (pow 2 10)) 'integer)
+             (assert-eq (str (pow 2 64)) "18446744073709551616")
+             (assert-eq (type-of (pow (big 2) 3)) 'big-integer)
+             (assert-eq (pow 1 100000000000) 1)
+             (assert-eq (pow -1 100000000001) -1)
+             (assert-eq (string-length (number->string (pow 2 100000) 2)) 100001)
+             (assert-error (pow 3 100000000000))
+             (assert-error (pow (factorial 25) 1000000)))
+
+         (it "convert to strings"
+             (assert-eq (number->string (factorial 25)) "15511210043330985984000000")
+             (assert-eq (number->string (pow 2 64) 16) "10000000000000000")
+             (assert-eq (number->formatted-string (factorial 25) '((grouping . ","))) "15,511,210,043,330,985,984,000,000")))
@@ -71,4 +71,33 @@
              (assert-error (right-shift '(a b) 2))
              (assert-error (right-shift 2 'a))
              (assert-error (right-shift 2 '(a b))))
-)
+
+         (it "can bit-and, bit-or, and bit-xor any number of integers"
+             (assert-eq (bit-and 0x0a 0x18) 0x08)
+             (assert-eq (bit-and 0xff 0xf0 0x3c) 0x30)
+             (assert-eq (bit-or 0x0a 0x05) 0x0f)
+             (assert-eq (bit-or 0x01 0x02 0x04 0x08) 0x0f)
+             (assert-eq (bit-xor 0x0f 0x05) 0x0a)
+             (assert-eq (bit-xor 0x0f 0x05 0x0a) 0x00)
+             (assert-eq (bit-and -1 0xff) 0xff)
+
+             (assert-error (bit-and 1))
+             (assert-error (bit-or 1 'a))
+             (assert-error (bit-xor 1 2 1.5)))
+
+         (it "can bit-not as a signed 64 bit integer"
+             (assert-eq (bit-not 0) -1)
+             (assert-eq (bit-not 0x0a) -11)
+             (assert-eq (bit-and (bit-not 0x0a) 0xff) 0xf5)
+
+             (assert-error (bit-not "1")))
+
+         (it "can shift-left and shift-right"
+             (assert-eq (shift-left 0x05 4) 0x50)
+             (assert-eq (shift-left 1 32) 0x100000000)
+             (assert-eq (shift-right 0x50 4) 0x05)
+             (assert-eq (shift-right -16 2) -4)
+
+             (assert-error (shift-left 1 -1))
+             (assert-error (shift-right 'a 2))
+             (assert-error (shift-right 2 '(a b)))))
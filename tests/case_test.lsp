@@ -78,3 +78,80 @@
                    (assert-eq (multi-test-func 9)
                               "many")))
 
+
+(define (fallthrough-func x)
+  (let ((steps '()))
+    (case x
+      ((0) (set! steps (cons 'zero steps))
+       (fallthrough))
+      ((1) (set! steps (cons 'one steps))
+       (fallthrough))
+      ((2) (set! steps (cons 'two steps)))
+      ((3) (set! steps (cons 'three steps)))
+      (else (set! steps (cons 'other steps))))
+    (reverse steps)))
+
+(define (shared-body-func x)
+  (case x
+    ((a) (fallthrough))
+    ((b) "a or b")
+    (else "neither")))
+
+(context "case fallthrough"
+
+         ()
+
+         (it "continues into the following clause"
+             (assert-eq (fallthrough-func 1) '(one two))
+             (assert-eq (fallthrough-func 0) '(zero one two)))
+
+         (it "lets clauses share a body"
+             (assert-eq (shared-body-func 'a) "a or b")
+             (assert-eq (shared-body-func 'b) "a or b")
+             (assert-eq (shared-body-func 'c) "neither"))
+
+         (it "does not fall through from normal clauses"
+             (assert-eq (fallthrough-func 2) '(two))
+             (assert-eq (fallthrough-func 3) '(three))
+             (assert-eq (fallthrough-func 9) '(other)))
+
+         (it "stops at the last clause"
+             (assert-nil (case 1 ((1) (fallthrough))))))
+
+
+(define (api-kind name)
+  (case name
+    ((bind-event register-game) 'setup)
+    ((game-event game-heartbeat) 'event)
+    (else 'unknown)))
+
+(context "case dispatch"
+
+         ()
+
+         (it "matches symbols"
+             (assert-eq (api-kind 'register-game) 'setup)
+             (assert-eq (api-kind 'game-heartbeat) 'event)
+             (assert-eq (api-kind 'remove-game) 'unknown))
+
+         (it "matches integers"
+             (assert-eq (case (* 2 3)
+                          ((2 3 5 7) 'prime)
+                          ((1 4 6 8 9) 'composite))
+                        'composite))
+
+         (it "is nil when nothing matches and there is no else"
+             (assert-nil (case 'x
+                           ((a) 1)
+                           ((b) 2))))
+
+         (it "evaluates the key once and only the matching body"
+             (define key-count 0)
+             (define evaluated '())
+             (assert-eq (case (begin (set! key-count (+ key-count 1)) 'b)
+                          ((a) (set! evaluated (cons 'a evaluated)) 1)
+                          ((b) (set! evaluated (cons 'b evaluated)) 2)
+                          (else (set! evaluated (cons 'else evaluated)) 3))
+                        2)
+             (assert-eq key-count 1)
+             (assert-eq evaluated '(b))))
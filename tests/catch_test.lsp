@@ -0,0 +1,41 @@
+;;; -*- mode: Scheme -*-
+
+(context "catch and throw"
+
+         ((define (find-first pred l)
+            (catch 'found
+              (for-each (lambda (x)
+                          (if (pred x) (throw 'found x)))
+                        l)
+              #f))
+          (define (thrower v) (throw 'outer v))
+          (define (middle v) (+ 1 (thrower v))))
+
+         (it "returns the body's value when nothing is thrown"
+             (assert-eq (catch 'tag 1 2 3) 3))
+
+         (it "returns the thrown value"
+             (assert-eq (catch 'tag (+ 1 (throw 'tag 10))) 10)
+             (assert-eq (find-first even? '(1 3 4 5 6)) 4)
+             (assert-false (find-first even? '(1 3 5))))
+
+         (it "unwinds past function frames"
+             (assert-eq (catch 'outer (* 2 (middle 5))) 5))
+
+         (it "goes to the nearest catch with the tag"
+             (assert-eq (catch 'a (+ 1 (catch 'a (throw 'a 1)))) 2)
+             (assert-eq (catch 'a (+ 1 (catch 'b (throw 'a 1)))) 1)
+             (assert-eq (catch 'a (+ 1 (catch 'b (throw 'b 1)))) 2))
+
+         (it "is not intercepted by guard or on-error"
+             (assert-eq (catch 'a (guard (e (#t 'guarded)) (throw 'a 1))) 1)
+             (assert-eq (catch 'a (on-error (throw 'a 1) (lambda (e) 'handled))) 1))
+
+         (it "errors at the throw when there is no matching catch"
+             (assert-error (throw 'nowhere 1))
+             (assert-error (catch 'a (throw 'b 1)))
+             (assert-eq (on-error (throw 'nowhere 1) (lambda (e) 'handled)) 'handled)
+             (assert-eq (guard (e (#t 'guarded)) (catch 'a (throw 'b 1))) 'guarded))
+
+         (it "only catches while the catch is being evaluated"
+             (assert-error ((catch 'k (lambda () (throw 'k 1)))))))
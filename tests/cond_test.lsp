@@ -31,4 +31,40 @@
              (assert-eq (cond (#f 1 2 3)
                               (#f 4 5 6)
                               (else 7 8 9))
-                        9)))
+                        9))
+
+         (it "passes the value of the test to the function after =>"
+             (assert-eq (cond ((assq 'b '((a . 1) (b . 2))) => cdr)
+                              (else 'none))
+                        2)
+             (assert-eq (cond ((memq 'z '(x y)) => car)
+                              ((memq 'y '(x y)) => length)
+                              (else 'none))
+                        1)
+             (assert-eq (cond (5 => (lambda (x) (* x x))))
+                        25)
+             (assert-error (cond (5 => 6))))
+
+         (it "supports => in tail position"
+             (define (lookup key)
+               (cond ((assq key '((a . 1) (b . 2))) => cdr)
+                     (else #f)))
+             (assert-eq (lookup 'a) 1)
+             (assert-false (lookup 'c)))
+
+         (it "falls back to else when no arrow clause matches"
+             (assert-eq (cond (#f => car)
+                              (else 'fallback))
+                        'fallback))
+
+         (it "only evaluates tests up to the first true one"
+             (define evaluated '())
+             (assert-eq (cond ((begin (set! evaluated (cons 1 evaluated)) #f) 1)
+                              ((begin (set! evaluated (cons 2 evaluated)) 2) => (lambda (x) (+ x 1)))
+                              ((begin (set! evaluated (cons 3 evaluated)) #t) 3))
+                        3)
+             (assert-eq evaluated '(2 1)))
+
+         (it "is nil when no clause matches"
+             (assert-nil (cond (#f 1)
+                               (#f => car)))))
@@ -28,3 +28,59 @@
                    (assert-error (define "x" 4))
                    (assert-error (define ("x") 4))
                    (assert-error (define (+ x y) 42))))
+
+(define (opt a &optional (b 10) c)
+  (list a b c))
+
+(define (opt-default-uses-earlier a &optional (b (* a 2)))
+  (list a b))
+
+(define (opt-rest a &optional (b 1) . more)
+  (list a b more))
+
+(define (keyed a &key (width 80) (height 24) title)
+  (list a width height title))
+
+(define (mixed a &optional (b 2) &key (c 3))
+  (list a b c))
+
+(context "optional and keyword parameters"
+
+         ()
+
+         (it "uses defaults for missing optionals"
+             (assert-eq (opt 1) '(1 10 ()))
+             (assert-eq (opt 1 2) '(1 2 ()))
+             (assert-eq (opt 1 2 3) '(1 2 3))
+             (assert-eq (opt-default-uses-earlier 4) '(4 8))
+             (assert-eq (opt-default-uses-earlier 4 5) '(4 5)))
+
+         (it "collects extra arguments into a rest parameter"
+             (assert-eq (opt-rest 1) '(1 1 ()))
+             (assert-eq (opt-rest 1 2 3 4) '(1 2 (3 4))))
+
+         (it "accepts keyword arguments in any order"
+             (assert-eq (keyed 1) '(1 80 24 ()))
+             (assert-eq (keyed 1 height: 50) '(1 80 50 ()))
+             (assert-eq (keyed 1 title: "t" width: 10) '(1 10 24 "t"))
+             (assert-eq (keyed 1 height: 2 width: 3) '(1 3 2 ())))
+
+         (it "combines optionals and keywords"
+             (assert-eq (mixed 1) '(1 2 3))
+             (assert-eq (mixed 1 5) '(1 5 3))
+             (assert-eq (mixed 1 5 c: 6) '(1 5 6)))
+
+         (it "counts only required parameters"
+             (assert-error (opt))
+             (assert-error (opt 1 2 3 4))
+             (assert-eq ((lambda (&optional x) x)) '()))
+
+         (it "rejects bad keyword arguments"
+             (assert-error (keyed 1 depth: 3))
+             (assert-error (keyed 1 width:))
+             (assert-error (keyed 1 80)))
+
+         (it "rejects malformed parameter lists"
+             (assert-error ((lambda (a &optional (b 1 2)) a) 1))
+             (assert-error ((lambda (a &key b . c) a) 1))
+             (assert-error ((lambda (&key a &optional b) a)))))
@@ -28,4 +28,40 @@
              (assert-error (do ((1 2)) (#t) (+ 1 2))))
 
          (it "rejects non-list test"
-             (assert-error (do ((x 1)) #t (+ 1 2)))))
+             (assert-error (do ((x 1)) #t (+ 1 2))))
+
+         (it "sums 1 to n"
+             (define (sum-to n)
+               (do ((i 1 (+ i 1))
+                    (sum 0 (+ sum i)))
+                   ((> i n) sum)))
+             (assert-eq (sum-to 10) 55)
+             (assert-eq (sum-to 0) 0))
+
+         (it "steps all variables against the old bindings"
+             (assert-eq (do ((a 0 b)
+                             (b 1 (+ a b))
+                             (i 0 (+ i 1)))
+                            ((== i 10) a))
+                        55)
+             (assert-eq (do ((x 1 y)
+                             (y 2 x)
+                             (i 0 (+ i 1)))
+                            ((== i 3) (list x y)))
+                        '(2 1)))
+
+         (it "evaluates the body for side effects and returns the last result form"
+             (define seen '())
+             (assert-eq (do ((i 0 (+ i 1)))
+                            ((== i 3) 'ignored (reverse seen))
+                          (set! seen (cons i seen)))
+                        '(0 1 2)))
+
+         (it "is nil without result forms"
+             (assert-nil (do ((i 0 (+ i 1)))
+                             ((== i 3))
+                           i)))
+
+         (it "reports errors in step expressions"
+             (assert-error (do ((i 0 (error "bad step")))
+                               ((== i 3))))))
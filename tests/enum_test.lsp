@@ -0,0 +1,51 @@
+;;; -*- mode: Scheme -*-
+
+(define-enum color (red green blue))
+
+(define-enum mode ((off 0) (slow 10) fast (max 255)))
+
+(context "define-enum"
+
+         ()
+
+         (it "numbers members from zero"
+             (assert-eq color:red 0)
+             (assert-eq color:green 1)
+             (assert-eq color:blue 2))
+
+         (it "uses explicit values and continues from them"
+             (assert-eq mode:off 0)
+             (assert-eq mode:slow 10)
+             (assert-eq mode:fast 11)
+             (assert-eq mode:max 255))
+
+         (it "converts values to names"
+             (assert-eq (color->string color:green) "green")
+             (assert-eq (mode->string 11) "fast")
+             (assert-error (color->string 7))
+             (assert-error (color->string "red")))
+
+         (it "converts names to values"
+             (assert-eq (string->color "blue") 2)
+             (assert-eq (string->mode "max") 255)
+             (assert-eq (string->mode 'slow) 10)
+             (assert-error (string->color "purple")))
+
+         (it "rejects bad definitions"
+             (assert-error (define-enum 5 (a b)))
+             (assert-error (define-enum bad ()))
+             (assert-error (define-enum bad (a 5)))
+             (assert-error (define-enum bad ((a "one"))))
+             (assert-error (define-enum bad (a b a))))
+
+         (it "binds nothing when a member is bad"
+             (assert-eq (on-error (define-enum partial (first second first))
+                                  (lambda (err) 'failed))
+                        'failed)
+             (assert-nil partial:first)
+             (assert-nil partial:second)
+             (assert-eq (on-error (define-enum halfway (first (second "two")))
+                                  (lambda (err) 'failed))
+                        'failed)
+             (assert-nil halfway:first)
+             (assert-error (string->halfway "first"))))
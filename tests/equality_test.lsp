@@ -11,4 +11,33 @@
              (assert-false (eq? (car (alist '((a.1)))) 42))
              (assert-false (eq? 42 "42"))
              (assert-false (eq? (alist '((a.1))) (alist '((a.1) (b.2)))))
-             (assert-false (eq? '(1 2) '(1 2 3)))))
+             (assert-false (eq? '(1 2) '(1 2 3))))
+
+         (it equal?
+             (assert-true (equal? (list 1 (list 2 "three") 'four) (list 1 (list 2 "three") 'four)))
+             (assert-false (equal? '(1 (2 3)) '(1 (2 4))))
+             (assert-false (equal? '(1 2) '(1 2 3)))
+             (assert-true (equal? '(1 . 2) (cons 1 2)))
+             (assert-true (equal? 1 1.0))
+             (assert-true (equal? '(1 2.0) '(1.0 2)))
+             (assert-false (eq? 1 1.0))
+             (assert-false (equal? 1 "1"))
+             (assert-true (equal? '() '()))
+             (assert-false (equal? '() '(1)))
+             (assert-true (equal? {a: 1 b: {c: '(1 2)}} {b: {c: '(1 2.0)} a: 1}))
+             (assert-false (equal? {a: 1 b: {c: 2}} {a: 1 b: {c: 3}}))
+             (assert-false (equal? {a: 1} {a: 1 b: 2}))
+             (assert-true (equal? (acons 'a (list 1 2) (acons 'b 3 nil))
+                                  (acons 'b 3.0 (acons 'a (list 1 2.0) nil))))
+             (assert-false (equal? (acons 'a (list 1 2) nil) (acons 'a (list 1 3) nil))))
+
+         (it "equal? handles cycles"
+             (define cycle-a (list 1 2))
+             (set-cdr! (cdr cycle-a) cycle-a)
+             (define cycle-b (list 1 2))
+             (set-cdr! (cdr cycle-b) cycle-b)
+             (define cycle-c (list 1 3))
+             (set-cdr! (cdr cycle-c) cycle-c)
+             (assert-true (equal? cycle-a cycle-a))
+             (assert-true (equal? cycle-a cycle-b))
+             (assert-false (equal? cycle-a cycle-c))))
@@ -26,7 +26,10 @@
              (assert-error (filter even? 5)))
 
          (it "rejects a non-boolean predicate"
-             (assert-error (filter + '(1 2)))))
+             (assert-error (filter + '(1 2))))
+
+         (it "propagates errors from the predicate"
+             (assert-error (filter (lambda (x) (error "bad")) '(1 2)))))
 
 (context remove
 
@@ -54,4 +57,88 @@
              (assert-error (remove even? 5)))
 
          (it "rejects a non-boolean predicate"
-             (assert-error (remove + '(1 2)))))
+             (assert-error (remove + '(1 2))))
+
+         (it "propagates errors from the predicate"
+             (assert-error (remove (lambda (x) (error "bad")) '(1 2)))))
+
+(context take-while
+
+         ()
+
+         (it "takes every element when all match"
+             (assert-eq (take-while even? '(2 4 6))
+                        '(2 4 6)))
+
+         (it "takes nothing when the first doesn't match"
+             (assert-eq (take-while even? '(1 2 4))
+                        '()))
+
+         (it "stops at the first failing element"
+             (assert-eq (take-while even? '(2 4 5 6))
+                        '(2 4)))
+
+         (it "takes nothing from an empty list"
+             (assert-eq (take-while even? '())
+                        '()))
+
+         (it "doesn't apply the predicate past the first failure"
+             (assert-eq (take-while (lambda (x) (< x 3)) '(1 2 3 foo))
+                        '(1 2))))
+
+(context drop-while
+
+         ()
+
+         (it "drops every element when all match"
+             (assert-eq (drop-while even? '(2 4 6))
+                        '()))
+
+         (it "drops nothing when the first doesn't match"
+             (assert-eq (drop-while even? '(1 2 4))
+                        '(1 2 4)))
+
+         (it "drops nothing from an empty list"
+             (assert-eq (drop-while even? '())
+                        '()))
+
+         (it "returns the rest from the first failing element"
+             (assert-eq (drop-while even? '(2 4 5 6))
+                        '(5 6))))
+
+(context span
+
+         ()
+
+         (it "splits where all match"
+             (assert-eq (call-with-values (lambda () (span even? '(2 4 6))) list)
+                        '((2 4 6) ())))
+
+         (it "splits where none match"
+             (assert-eq (call-with-values (lambda () (span even? '(1 3))) list)
+                        '(() (1 3))))
+
+         (it "splits at the first failing element"
+             (assert-eq (call-with-values (lambda () (span even? '(2 4 5 6))) list)
+                        '((2 4) (5 6)))))
+
+(context take-while-errors
+
+         ()
+
+         (it "rejects a non-function predicate"
+             (assert-error (take-while 5 '()))
+             (assert-error (drop-while 5 '()))
+             (assert-error (span 5 '())))
+
+         (it "rejects a non-list source list"
+             (assert-error (take-while even? 5))
+             (assert-error (drop-while even? 5))
+             (assert-error (span even? 5)))
+
+         (it "propagates errors from the predicate"
+             (assert-error (take-while (lambda (x) (car x)) '(1 2)))
+             (assert-error (drop-while (lambda (x) (car x)) '(1 2)))
+             (assert-error (span (lambda (x) (car x)) '(1 2)))
+             (assert-error (take-while (lambda (x) (error "bad")) '(1 2)))
+             (assert-error (drop-while (lambda (x) (error "bad")) '(1 2)))))
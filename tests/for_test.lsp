@@ -0,0 +1,58 @@
+;;; -*- mode: Scheme -*-
+
+(context "for"
+
+         ()
+
+         (it "collects the body value for each element"
+             (assert-eq (for ((x (iota 3))) (* x x))
+                        '(0 1 4)))
+
+         (it "iterates several lists in parallel"
+             (assert-eq (for ((x '(1 2 3)) (y '(10 20 30))) (+ x y))
+                        '(11 22 33)))
+
+         (it "stops at the end of the shortest list"
+             (assert-eq (for ((x '(1 2 3 4)) (y '(a b))) (list x y))
+                        '((1 a) (2 b))))
+
+         (it "filters with a guard"
+             (assert-eq (for ((x (iota 10)))
+                          (when (even? x))
+                          x)
+                        '(0 2 4 6 8)))
+
+         (it "evaluates a multi-form body"
+             (assert-eq (for ((x '(1 2)))
+                          (define y (* x 10))
+                          (+ x y))
+                        '(11 22)))
+
+         (it "returns an empty list for an empty input"
+             (assert-eq (for ((x '())) x) '()))
+
+         (it "rejects bad clauses"
+             (assert-error (for (x '(1 2)) x))
+             (assert-error (for ((1 '(1 2))) 1))
+             (assert-error (for ((x 5)) x))
+             (assert-error (for ((x '(1 2))) (when #t)))))
+
+(context "for*"
+
+         ()
+
+         (it "iterates over the cross product"
+             (assert-eq (for* ((x '(1 2)) (y '(a b))) (list x y))
+                        '((1 a) (1 b) (2 a) (2 b))))
+
+         (it "filters with a guard"
+             (assert-eq (for* ((x (iota 4)) (y (iota 4)))
+                          (when (< x y))
+                          (list x y))
+                        '((0 1) (0 2) (0 3) (1 2) (1 3) (2 3))))
+
+         (it "produces nothing when any list is empty"
+             (assert-eq (for* ((x '(1 2)) (y '())) (list x y)) '()))
+
+         (it "propagates errors"
+             (assert-error (for* ((x '(1 2))) (error "oops")))))
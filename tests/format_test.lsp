@@ -0,0 +1,39 @@
+;;; -*- mode: Scheme -*-
+
+(context "format"
+
+         ()
+
+         (it "substitutes values"
+             (assert-eq (format #f "a ~a b" "x") "a x b")
+             (assert-eq (format #f "~s" "x") "\"x\"")
+             (assert-eq (format #f "~a and ~a" 1 '(2 3)) "1 and (2 3)")
+             (assert-eq (format #f "~a" nil) "()"))
+
+         (it "formats integers and floats"
+             (assert-eq (format #f "~d items" 42) "42 items")
+             (assert-eq (format #f "[~5d]" 42) "[   42]")
+             (assert-eq (format #f "~f" 2.5) "2.5")
+             (assert-eq (format #f "~f" 3) "3")
+             (assert-eq (format #f "~f" 16777217) "16777217")
+             (assert-eq (format #f "~f" -9007199254740993) "-9007199254740993")
+             (assert-eq (format #f "[~6f]" 1.25) "[  1.25]")
+             (assert-error (format #f "~d" 1.5))
+             (assert-error (format #f "~f" "x")))
+
+         (it "pads ~a on the right, or the left with @"
+             (assert-eq (format #f "[~4a]" "ab") "[ab  ]")
+             (assert-eq (format #f "[~4@a]" "ab") "[  ab]"))
+
+         (it "handles newlines and tildes"
+             (assert-eq (format #f "a~%b") "a\nb")
+             (assert-eq (format #f "~~") "~"))
+
+         (it "requires as many arguments as substitutions"
+             (assert-error (format #f "~a ~a" 1))
+             (assert-error (format #f "~a" 1 2))
+             (assert-error (format #f "~d"))
+             (assert-error (format #f "oops ~")))
+
+         (it "requires a boolean or port destination"
+             (assert-error (format "~a" 1))))
@@ -165,7 +165,7 @@
                     (g {parent*: f  foo: (lambda () (+ 1 (apply-slot-super foo: '(1 2))))}))
                (assert-eq (send g foo:)
                           7))
-               (assert-error (apply-slot-super foo:)))) ;only usable in a frame
+               (assert-error (apply-slot-super foo:))) ;only usable in a frame
 
          (it calling-super-sugar
              (let* ((f {foo: (lambda () 42)})
@@ -261,7 +261,15 @@
              (assert-error (frame-keys 4))
              (assert-error (frame-values '()))
              (assert-error (frame-values ""))
-             (assert-error (frame-values 4))))
+             (assert-error (frame-values 4)))
+
+         (it "frame->alist"
+             (assert-eq (frame->alist {b: 2 a: 1 c: "three"})
+                        (alist '(("a" . 1) ("b" . 2) ("c" . "three"))))
+             (assert-eq (frame->alist {})
+                        '())
+             (assert-error (frame->alist '()))
+             (assert-error (frame->alist '((a . 1))))))
 
 (context "Frame functions"
 
@@ -0,0 +1,44 @@
+;;; -*- mode: Scheme -*-
+
+(define (documented a b)
+  "Adds a and b."
+  (+ a b))
+
+(define (undocumented x)
+  "just a string result")
+
+(define (spread a . rest)
+  rest)
+
+(define (optional a &optional (b 1))
+  (+ a b))
+
+(context "function introspection"
+
+         ()
+
+         (it "returns the function's name"
+             (assert-eq (function-name documented) "documented")
+             (assert-eq (function-name (lambda (x) x)) "unnamed"))
+
+         (it "returns the docstring, or an empty string"
+             (assert-eq (function-documentation documented) "Adds a and b.")
+             (assert-eq (function-documentation undocumented) "")
+             (assert-eq (doc documented) "Adds a and b.")
+             (assert-eq (doc undocumented) "")
+             (assert-eq (undocumented 1) "just a string result"))
+
+         (it "returns the required count and a varargs flag"
+             (assert-eq (function-arity documented) '(2 #f))
+             (assert-eq (function-arity spread) '(1 #t))
+             (assert-eq (function-arity optional) '(1 #t)))
+
+         (it "returns the parameter list"
+             (assert-eq (function-params documented) '(a b))
+             (assert-eq (function-params spread) '(a . rest)))
+
+         (it "errors on non-functions"
+             (assert-error (function-name 5))
+             (assert-error (function-documentation +))
+             (assert-error (function-arity "f"))
+             (assert-error (function-params '(a b)))))
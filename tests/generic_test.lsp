@@ -0,0 +1,57 @@
+;;; -*- mode: Scheme -*-
+
+(define-generic describe)
+
+(define-method describe integer (lambda (x) (str "integer " x)))
+(define-method describe string (lambda (x) (str "string " x)))
+
+(define-generic combine)
+
+(define-method combine integer (lambda (x y) (+ x y)))
+(define-method combine list (lambda (x y) (append x y)))
+(define-method combine default (lambda (x y) (list x y)))
+
+(context "generic functions"
+
+         ()
+
+         (it "dispatches on the type of the first argument"
+             (assert-eq (describe 42) "integer 42")
+             (assert-eq (describe "hi") "string hi"))
+
+         (it "passes all arguments to the method"
+             (assert-eq (combine 1 2) 3)
+             (assert-eq (combine '(1) '(2)) '(1 2)))
+
+         (it "falls back to the default method"
+             (assert-eq (combine 'a 'b) '(a b)))
+
+         (it "errors when no method applies"
+             (assert-error (describe 'sym)))
+
+         (it "can replace a method"
+             (define-generic shape)
+             (define-method shape integer (lambda (x) 'old))
+             (define-method shape integer (lambda (x) 'new))
+             (assert-eq (shape 1) 'new))
+
+         (it "rejects bad definitions"
+             (assert-error (define-method car integer (lambda (x) x)))
+             (assert-error (define-method describe 5 (lambda (x) x)))
+             (assert-error (define-method describe float 5))))
+
+(context "type-of"
+
+         ()
+
+         (it "names the type of a value"
+             (assert-eq (type-of 1) 'integer)
+             (assert-eq (type-of 1.5) 'float)
+             (assert-eq (type-of "a") 'string)
+             (assert-eq (type-of 'a) 'symbol)
+             (assert-eq (type-of '(1 2)) 'list)
+             (assert-eq (type-of '()) 'nil)
+             (assert-eq (type-of #t) 'boolean)
+             (assert-eq (type-of car) 'primitive)
+             (assert-eq (type-of (lambda (x) x)) 'function)
+             (assert-eq (type-of (make-hash-table)) 'hash-table)))
@@ -51,4 +51,10 @@
                (assert-neq (gensym-naked 'ho)
                           ho-sym)
                (assert-neq (gensym-naked 'hi)
-                          hi-sym))))
+                          hi-sym)))
+
+         (it gensym-is-distinct-from-read-symbols
+             (let ((sym (gensym 'hyg)))
+               (assert-eq sym sym)
+               (assert-false (eq? sym (intern (str sym))))
+               (assert-false (eq? (gensym 'taken) 'taken-0)))))
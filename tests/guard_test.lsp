@@ -0,0 +1,55 @@
+;;; -*- mode: Scheme -*-
+
+(context "guard"
+
+         ((define (raiser x) (raise x)))
+
+         (it "returns the body's value when nothing is raised"
+             (assert-eq (guard (e (#t 'handled))
+                          (+ 1 2))
+                        3))
+
+         (it "binds the raised object"
+             (assert-eq (guard (e (#t e))
+                          (raise 'oops))
+                        'oops)
+             (assert-eq (guard (e ((pair? e) (car e)))
+                          (+ 1 (raise '(io-error "disk full"))))
+                        'io-error))
+
+         (it "selects the clause matching the category"
+             (assert-eq (guard (e ((eq? e 'io-error) 'io)
+                                  ((eq? e 'type-error) 'type))
+                          (raise 'type-error))
+                        'type))
+
+         (it "uses else when nothing else matches"
+             (assert-eq (guard (e ((eq? e 'io-error) 'io)
+                                  (else 'other))
+                          (raise 'type-error))
+                        'other))
+
+         (it "returns the test value of a clause without a body"
+             (assert-eq (guard (e ((memq e '(a b c))))
+                          (raise 'b))
+                        '(b c)))
+
+         (it "binds the message of other errors"
+             (assert-true (guard (e ((string? e) #t))
+                            (error "failed"))))
+
+         (it "re-raises when no clause matches"
+             (assert-error (guard (e ((eq? e 'io-error) 'io))
+                             (raise 'type-error)))
+             (assert-eq (guard (outer (#t outer))
+                          (guard (inner ((eq? inner 'io-error) 'io))
+                            (raise 'type-error)))
+                        'type-error))
+
+         (it "sees objects raised inside function calls"
+             (assert-eq (guard (e ((symbol? e) e))
+                          (+ 1 (raiser 'deep)))
+                        'deep))
+
+         (it "rejects a malformed spec"
+             (assert-error (guard 5 (raise 'x)))))
@@ -0,0 +1,140 @@
+;;; -*- mode: Scheme -*-
+
+(context "hash tables"
+
+         ((define h (make-hash-table 100)))
+
+         (it "makes hash tables"
+             (assert-true (hash-table? (make-hash-table)))
+             (assert-true (hash-table? (make-hash-table 10)))
+             (assert-false (hash-table? '((a . 1))))
+             (assert-error (make-hash-table -1))
+             (assert-error (make-hash-table "10")))
+
+         (it "starts empty"
+             (assert-eq (hash-table-size (make-hash-table 50)) 0))
+
+         (it "stores and retrieves values"
+             (hash-set! h 'a 1)
+             (hash-set! h "b" 2)
+             (hash-set! h 3 'three)
+             (assert-eq (hash-ref h 'a) 1)
+             (assert-eq (hash-ref h "b") 2)
+             (assert-eq (hash-ref h 3) 'three)
+             (assert-nil (hash-ref h 'missing))
+             (assert-eq (hash-ref h 'missing 'default) 'default)
+             (assert-true (hash-has-key? h 'a))
+             (assert-false (hash-has-key? h 'missing)))
+
+         (it "compares keys by value"
+             (hash-set! h '(1 2) 'list-key)
+             (assert-eq (hash-ref h (list 1 2)) 'list-key)
+             (assert-false (hash-has-key? h "a")))
+
+         (it "finds keys that are equal?"
+             (let ((table (make-hash-table)))
+               (hash-set! table 1 'one)
+               (assert-eq (hash-ref table 1.0) 'one)
+               (assert-eq (hash-ref table (big 1)) 'one)
+               (hash-set! table 1.0 'uno)
+               (assert-eq (hash-table-size table) 1)
+               (assert-eq (hash-ref table 1) 'uno)
+               (hash-set! table (list 1 (list 2 3)) 'nested)
+               (assert-eq (hash-ref table (list 1.0 (list 2 3.0))) 'nested)
+               (hash-set! table (acons 'a 1 (acons 'b 2 nil)) 'alist)
+               (assert-eq (hash-ref table (acons 'b 2 (acons 'a 1 nil))) 'alist)
+               (assert-false (hash-has-key? table (list 2 1)))
+               (assert-true (hash-remove! table 1.0))
+               (assert-false (hash-has-key? table 1))))
+
+         (it "keeps gensyms apart from symbols with the same name"
+             (let* ((table (make-hash-table))
+                    (g (gensym "hash-key"))
+                    (s (intern (str g))))
+               (hash-set! table g 'generated)
+               (assert-false (hash-has-key? table s))
+               (hash-set! table s 'interned)
+               (assert-eq (hash-table-size table) 2)
+               (assert-eq (hash-ref table g) 'generated)
+               (assert-eq (hash-ref table s) 'interned)))
+
+         (it "tracks size across inserts, overwrites, and removals"
+             (let ((table (make-hash-table)))
+               (hash-set! table 'a 1)
+               (hash-set! table 'b 2)
+               (hash-set! table 'c 3)
+               (assert-eq (hash-table-size table) 3)
+               (hash-set! table 'a 10)
+               (hash-set! table 'b 20)
+               (assert-eq (hash-table-size table) 3)
+               (assert-eq (hash-ref table 'a) 10)
+               (assert-true (hash-remove! table 'a))
+               (assert-eq (hash-table-size table) 2)
+               (assert-false (hash-remove! table 'a))
+               (assert-eq (hash-table-size table) 2)
+               (hash-remove! table 'b)
+               (hash-remove! table 'c)
+               (assert-eq (hash-table-size table) 0)))
+
+         (it "lists keys, values, and entries"
+             (let ((table (make-hash-table)))
+               (hash-set! table 'a 1)
+               (hash-set! table 'b 2)
+               (assert-eq (sort (hash-table-values table) <) '(1 2))
+               (assert-eq (length (hash-table-keys table)) 2)
+               (assert-memq (hash-table-keys table) 'a)
+               (assert-eq (cdr (assoc 'b (hash-table->alist table))) 2)))
+
+         (it "rejects non hash tables"
+             (assert-error (hash-set! '() 'a 1))
+             (assert-error (hash-ref 5 'a))
+             (assert-error (hash-table-size '((a . 1)))))
+
+         (it "copies tables"
+             (let* ((table (make-hash-table))
+                    (copy (begin (hash-set! table 'a 1) (hash-table-copy table))))
+               (hash-set! copy 'b 2)
+               (assert-eq (hash-table-size table) 1)
+               (assert-eq (hash-table-size copy) 2)))
+
+         (it "merges in place with the source winning"
+             (let ((dest (make-hash-table))
+                   (src (make-hash-table)))
+               (hash-set! dest 'a 1)
+               (hash-set! dest 'b 2)
+               (hash-set! src 'b 20)
+               (hash-set! src 'c 30)
+               (assert-eq (hash-table-merge! dest src) dest)
+               (assert-eq (hash-table-size dest) 3)
+               (assert-eq (hash-ref dest 'a) 1)
+               (assert-eq (hash-ref dest 'b) 20)
+               (assert-eq (hash-ref dest 'c) 30)
+               (assert-eq (hash-table-size src) 2)))
+
+         (it "merges with a conflict resolver"
+             (let ((dest (make-hash-table))
+                   (src (make-hash-table)))
+               (hash-set! dest 'a 1)
+               (hash-set! dest 'b 2)
+               (hash-set! src 'b 20)
+               (hash-set! src 'c 30)
+               (hash-table-merge! dest src (lambda (key old new) (+ old new)))
+               (assert-eq (hash-ref dest 'a) 1)
+               (assert-eq (hash-ref dest 'b) 22)
+               (assert-eq (hash-ref dest 'c) 30)))
+
+         (it "merges without changing either table"
+             (let ((dest (make-hash-table))
+                   (src (make-hash-table)))
+               (hash-set! dest 'a 1)
+               (hash-set! src 'a 10)
+               (hash-set! src 'b 20)
+               (let ((merged (hash-table-merge dest src (lambda (k old new) (+ old new)))))
+                 (assert-eq (hash-ref merged 'a) 11)
+                 (assert-eq (hash-ref merged 'b) 20)
+                 (assert-eq (hash-table-size dest) 1)
+                 (assert-eq (hash-ref dest 'a) 1))))
+
+         (it "rejects bad merge arguments"
+             (assert-error (hash-table-merge! (make-hash-table) '((a . 1))))
+             (assert-error (hash-table-merge (make-hash-table) (make-hash-table) 5))))
@@ -49,4 +49,82 @@
              (assert-eq (unless #f (set! when1 42) 1)
                         1)
              (assert-eq when1
-                        42)))
+                        42))
+
+         (it when-unless-empty-body
+             (assert-nil (when #t))
+             (assert-nil (when #f))
+             (assert-nil (unless #t))
+             (assert-nil (unless #f))
+             (set! when1 1)
+             (when (set! when1 2))
+             (assert-eq when1 2)
+             (unless (set! when1 3))
+             (assert-eq when1 3)
+             (assert-error (when))
+             (assert-error (unless)))
+
+         (it when-unless-multi-form-body
+             (set! when1 '())
+             (assert-eq (when (< 1 2)
+                          (set! when1 (cons 'a when1))
+                          (set! when1 (cons 'b when1))
+                          'done)
+                        'done)
+             (assert-eq when1 '(b a))
+             (assert-eq (unless (> 1 2)
+                          (set! when1 (cons 'c when1))
+                          (length when1))
+                        3)
+             (assert-eq when1 '(c b a)))
+
+         (it when-unless-in-tail-position
+             (define (count-down n)
+               (unless (== n 0)
+                 (count-down (- n 1))))
+             (assert-nil (count-down 100000))
+             (define (empty-when x) (when x))
+             (assert-nil (empty-when #t))))
+
+(define if-let-x 'outer)
+
+(context "binding conditionals"
+
+         ()
+
+         (it if-let-truthy
+             (assert-eq (if-let (x (assq 'b '((a . 1) (b . 2))))
+                          (cdr x)
+                          'none)
+                        2))
+
+         (it if-let-falsy
+             (assert-eq (if-let (x (assq 'c '((a . 1) (b . 2))))
+                          (cdr x)
+                          'none)
+                        'none)
+             (assert-eq (if-let (x #f) 'yes 'no)
+                        'no)
+             (assert-nil (if-let (x '()) 'yes)))
+
+         (it if-let-scope
+             (assert-eq (if-let (if-let-x 5) if-let-x 'no)
+                        5)
+             (assert-eq (if-let (if-let-x #f) 'yes if-let-x)
+                        'outer)
+             (assert-eq if-let-x 'outer))
+
+         (it when-let-truthy
+             (assert-eq (when-let (x (+ 1 2)) 'ignored (* x 2))
+                        6))
+
+         (it when-let-falsy
+             (set! when1 1)
+             (assert-nil (when-let (x #f) (set! when1 42) x))
+             (assert-eq when1 1))
+
+         (it binding-errors
+             (assert-error (if-let x 1 2))
+             (assert-error (if-let (1 2) 1 2))
+             (assert-error (when-let (x) x))
+             (assert-error (when-let (x 1 2) x))))
@@ -0,0 +1,56 @@
+;;; -*- mode: Scheme -*-
+
+(context "with-input-from-string"
+
+         ()
+
+         (it "reads forms from the string"
+             (assert-eq (with-input-from-string "(a b) 42"
+                          (lambda ()
+                            (list (read) (read))))
+                        '((a b) 42)))
+
+         (it "returns the eof object when the string is exhausted"
+             (assert-true (eof-object? (with-input-from-string "42"
+                                         (lambda ()
+                                           (read)
+                                           (read))))))
+
+         (it "reads lines"
+             (assert-eq (with-input-from-string "first line
+second line"
+                          (lambda ()
+                            (list (read-line) (read-line))))
+                        '("first line" "second line"))
+             (assert-true (eof-object? (with-input-from-string ""
+                                         (lambda () (read-line))))))
+
+         (it "reads characters"
+             (assert-eq (with-input-from-string "ab"
+                          (lambda ()
+                            (list (read-char) (read-char))))
+                        '("a" "b"))
+             (assert-true (eof-object? (with-input-from-string ""
+                                         (lambda () (read-char))))))
+
+         (it "restores the previous input afterward"
+             (assert-eq (with-input-from-string "outer1 outer2"
+                          (lambda ()
+                            (let ((first (read))
+                                  (inner (with-input-from-string "inner"
+                                           (lambda () (read)))))
+                              (list first inner (read)))))
+                        '(outer1 inner outer2)))
+
+         (it "restores the previous input after an error"
+             (assert-eq (with-input-from-string "outer"
+                          (lambda ()
+                            (on-error (with-input-from-string "inner"
+                                        (lambda () (error "oops")))
+                                      (lambda (e) nil))
+                            (read)))
+                        'outer))
+
+         (it "rejects bad arguments"
+             (assert-error (with-input-from-string 42 (lambda () (read))))
+             (assert-error (with-input-from-string "42" 42))))
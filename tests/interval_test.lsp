@@ -27,3 +27,58 @@
              (assert-eq (interval 1) '(1))
              (assert-eq (interval 10) '(1 2 3 4 5 6 7 8 9 10))))
 
+
+(context "iota"
+
+         ()
+
+         (it "counts up from zero"
+             (assert-eq (iota 5) '(0 1 2 3 4))
+             (assert-eq (iota 0) '()))
+
+         (it "supports a start and step"
+             (assert-eq (iota 3 1) '(1 2 3))
+             (assert-eq (iota 4 10 -2) '(10 8 6 4))
+             (assert-eq (iota 3 0 0.5) '(0.0 0.5 1.0)))
+
+         (it "rejects bad arguments"
+             (assert-error (iota -1))
+             (assert-error (iota "3"))
+             (assert-error (iota 3 'a))))
+
+
+(context "range"
+
+         ()
+
+         (it "counts up from zero to before the end"
+             (assert-eq (range 5) '(0 1 2 3 4))
+             (assert-eq (range 0) '())
+             (assert-eq (range -2) '()))
+
+         (it "supports a start"
+             (assert-eq (range 2 5) '(2 3 4))
+             (assert-eq (range 5 5) '())
+             (assert-eq (range 5 2) '()))
+
+         (it "supports positive and negative steps"
+             (assert-eq (range 0 10 3) '(0 3 6 9))
+             (assert-eq (range 5 0 -1) '(5 4 3 2 1))
+             (assert-eq (range 10 -1 -5) '(10 5 0))
+             (assert-eq (range 0 5 -1) '()))
+
+         (it "stops at the limits of an integer"
+             (assert-eq (range 9223372036854775800 9223372036854775807 5)
+                        '(9223372036854775800 9223372036854775805))
+             (assert-eq (range 9223372036854775806 9223372036854775807 9223372036854775807)
+                        '(9223372036854775806))
+             (assert-eq (range -9223372036854775800 -9223372036854775808 -5)
+                        '(-9223372036854775800 -9223372036854775805)))
+
+         (it "composes with map and filter"
+             (assert-eq (map (lambda (x) (* x x)) (filter even? (range 6))) '(0 4 16)))
+
+         (it "rejects a zero step and non-integers"
+             (assert-error (range 0 5 0))
+             (assert-error (range 1.5))
+             (assert-error (range 0 'a))))
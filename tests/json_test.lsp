@@ -0,0 +1,47 @@
+;;; -*- mode: Scheme -*-
+
+(define json-doc "{\"a\":[1,2.5,\"x\"],\"b\":{\"c\":true,\"d\":null},\"e\":false}")
+
+(context "parse-json"
+
+         ()
+
+         (it "converts objects to alists and arrays to lists"
+             (assert-eq (cdr (assoc "a" (parse-json json-doc))) '(1 2.5 "x"))
+             (assert-true (cdr (assoc "c" (cdr (assoc "b" (parse-json json-doc))))))
+             (assert-false (cdr (assoc "e" (parse-json json-doc)))))
+
+         (it "converts null to nil"
+             (assert-nil (cdr (assoc "d" (cdr (assoc "b" (parse-json json-doc))))))
+             (assert-nil (parse-json "null")))
+
+         (it "errors on bad json"
+             (assert-error (parse-json "{bad"))
+             (assert-error (parse-json 42))))
+
+(context "to-json"
+
+         ()
+
+         (it "converts lisp values"
+             (assert-eq (to-json '(1 a "b" #t 1.5)) "[1,\"a\",\"b\",true,1.5]")
+             (assert-eq (to-json nil) "null")
+             (assert-eq (to-json (acons "k" 1 nil)) "{\"k\":1}"))
+
+         (it "round trips parse-json"
+             (assert-eq (to-json (parse-json json-doc)) json-doc))
+
+         (it "round trips empty objects"
+             (assert-true (nil? (parse-json "{}")))
+             (assert-eq (to-json (parse-json "{}")) "{}")
+             (assert-eq (to-json (parse-json "{\"b\":{},\"c\":null}"))
+                        "{\"b\":{},\"c\":null}"))
+
+         (it "writes empty arrays back as null, since they are nil"
+             (assert-nil (parse-json "[]"))
+             (assert-eq (to-json (parse-json "[]")) "null")
+             (assert-eq (to-json (parse-json "{\"a\":[]}")) "{\"a\":null}"))
+
+         (it "errors on values json can't represent"
+             (assert-error (to-json (make-hash-table)))
+             (assert-error (to-json (acons 1 2 nil)))))
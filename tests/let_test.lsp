@@ -31,6 +31,53 @@
                           3)))
 
 
+         (it let*-sequential-bindings
+             (assert-nil (let* ()))
+             (assert-eq (let* ((x 1)
+                               (y (+ x 1))
+                               (z (* y 10)))
+                          (list x y z))
+                        '(1 2 20))
+             (let ((x 10))
+               (assert-eq (let ((x 1)
+                                (y x))
+                            y)
+                          10)
+               (assert-eq (let* ((x 1)
+                                 (y x))
+                            y)
+                          1))
+             (assert-eq (let* ((x 1)
+                               (x (+ x 1)))
+                          x)
+                        2)
+             (assert-eq (let* ((x 1)
+                               (get-x (lambda () x))
+                               (x 2))
+                          (list x (get-x)))
+                        '(2 1))
+             (assert-error (let* ((x 1) (5 2)) x))
+             (assert-error (let* (5) 1)))
+
+         (it letrec-mutual-recursion
+             (assert-eq (letrec ((my-even? (lambda (n)
+                                              (if (== n 0)
+                                                  #t
+                                                  (my-odd? (- n 1)))))
+                                 (my-odd? (lambda (n)
+                                             (if (== n 0)
+                                                 #f
+                                                 (my-even? (- n 1))))))
+                          (list (my-even? 10) (my-odd? 7) (my-even? 3) (my-odd? 0)))
+                        '(#t #t #f #f))
+             (assert-eq (letrec ((f (lambda () g))
+                                 (g 5))
+                          (f))
+                        5)
+             (assert-nil (begin (letrec ((scoped 1)) scoped)
+                                scoped))
+             (assert-error (letrec ((5 1)) 1)))
+
          (it let-binding-scope
              (assert-nil (begin (let ((zz 2)) zz)
                                 zz)))
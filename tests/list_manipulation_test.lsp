@@ -37,6 +37,32 @@
                    (assert-eq (reverse 42)
                               42))
 
+         (it "reverse doesn't modify its argument"
+                   (assert-eq (let* ((l (list 'a 'b 'c))
+                                     (r (reverse l)))
+                                (list l r))
+                              '((a b c) (c b a))))
+
+         (it reverse!
+                   (assert-eq (reverse! (list 'a))
+                              '(a))
+                   (assert-eq (reverse! (list 'a 'b 'c 'd))
+                              '(d c b a))
+                   (assert-eq (reverse! (list))
+                              '())
+                   (assert-eq (reverse! 42)
+                              42)
+                   (assert-eq (let* ((l (list 'a 'b 'c))
+                                     (r (reverse! l)))
+                                (list l r))
+                              '((a) (c b a)))
+                   (assert-error (reverse! (cons 1 2)))
+                   (assert-error (reverse! '(1 2 . 3)))
+                   (assert-eq (let ((l (list 1 2 3)))
+                                (set-cdr! (cddr l) 4)
+                                (on-error (reverse! l) (lambda (e) l)))
+                              '(1 2 3 . 4)))
+
          (it flatten
                    (assert-eq (flatten '(1 2 3 4))
                               '(1 2 3 4))
@@ -73,13 +99,18 @@
              (assert-eq (partition odd? '(1 2 3 4 5 6 7 8 9))
                         '((1 3 5 7 9) (2 4 6 8)))
              (assert-eq (partition even? '(1 2 3 4 5 6 7 8 9))
-                        '((2 4 6 8) (1 3 5 7 9))))
+                        '((2 4 6 8) (1 3 5 7 9)))
+             (assert-eq (partition even? '())
+                        '(() ()))
+             (assert-eq (partition even? '(1 3))
+                        '(() (1 3))))
 
          (it partition-errors
              (assert-error (partition -1 '(1 2))) ;1st arg has to be non -ive if it's an int
              (assert-error (partition "hi" '(1 2)))  ;1st arg has to be int or function
              (assert-error (partition 1 "1 2")) ;2nd arg must be a list
-             (assert-error (partition odd? "1 2"))) ;2nd arg must be a list
+             (assert-error (partition odd? "1 2")) ;2nd arg must be a list
+             (assert-error (partition (lambda (x) (error "bad")) '(1 2)))) ;predicate errors propagate
 
          (it append
              (assert-eq (append list1 '(3 4))
@@ -122,7 +153,12 @@
                         '(1 2 3))
              (assert-eq (take 3 '(1 2 3 4 5))
                         '(1 2 3))
+             (assert-eq (take 5 '(1 2 3))
+                        '(1 2 3))
+             (assert-eq (take 2 '())
+                        '())
              (assert-error (take "1" '(1 2 3))) ;1st arg must be a number
+             (assert-error (take -1 '(1 2 3))) ;1st arg can't be negative
              (assert-error (take 1 4))) ;2nd arg must be a list
 
          (it drop
@@ -134,7 +170,12 @@
                         '())
              (assert-eq (drop 3 '(1 2 3 4 5))
                         '(4 5))
+             (assert-eq (drop 5 '(1 2 3))
+                        '())
+             (assert-eq (drop 2 '())
+                        '())
              (assert-error (drop "1" '(1 2 3))) ;1st arg must be a number
+             (assert-error (drop -1 [1 2 3])) ;1st arg can't be negative
              (assert-error (drop 1 4))) ;2nd arg must be a list
 
          (it list-head
@@ -186,4 +227,63 @@
              (assert-error (make-list "3" 1)) ;1st arg must be an integer
              (assert-error (make-list 3.4 1)) ;1st arg must be an integer
              (assert-error (make-list -3 1))) ;1st arg must be a non-negative integer
+
+         (it length
+             (assert-eq (length '()) 0)
+             (assert-eq (length '(1 2 3)) 3))
+
+         (it length+
+             (assert-eq (length+ '()) 0)
+             (assert-eq (length+ '(1 2 3)) 3)
+             (assert-eq (length+ '(1 2 3 4)) 4)
+             (assert-false (length+ '(1 2 . 3)))
+             (let ((circular (list 1 2 3)))
+               (set-cdr! (last-pair circular) circular)
+               (assert-false (length+ circular))))
+
+         (it length>=?
+             (assert-true (length>=? '(1 2 3) 2))
+             (assert-true (length>=? '(1 2 3) 3))
+             (assert-false (length>=? '(1 2 3) 4))
+             (assert-true (length>=? '() 0))
+             (assert-true (length>=? (interval 1 100000) 5))
+             (let ((circular (list 1 2 3)))
+               (set-cdr! (last-pair circular) circular)
+               (assert-true (length>=? circular 10)))
+             (assert-error (length>=? 5 1))
+             (assert-error (length>=? '(1 2) "1")))
 )
+
+(context "sorting"
+
+         ()
+
+         (it sort
+             (assert-eq (sort '(3 1 2) <) '(1 2 3))
+             (assert-eq (sort '(3 1 2) >) '(3 2 1))
+             (assert-eq (sort '() <) '())
+             (assert-eq (sort '(5) <) '(5))
+             (assert-eq (sort '("pear" "apple" "fig") string<?) '("apple" "fig" "pear")))
+
+         (it "sort doesn't evaluate the items it compares"
+             (assert-eq (sort '(b c a) (lambda (x y) (string<? (str x) (str y))))
+                        '(a b c)))
+
+         (it "sort leaves the original list untouched"
+             (assert-eq (let* ((l (list 3 1 2))
+                               (s (sort l <)))
+                          l)
+                        '(3 1 2)))
+
+         (it stable-sort
+             (assert-eq (stable-sort '((b . 1) (a . 0) (c . 1) (d . 0) (e . 1))
+                                     (lambda (x y) (< (cdr x) (cdr y))))
+                        '((a . 0) (d . 0) (b . 1) (c . 1) (e . 1)))
+             (assert-eq (stable-sort '() <) '())
+             (assert-eq (stable-sort '(5) <) '(5)))
+
+         (it "sort propagates comparator errors"
+             (assert-error (sort '(1 a 2) <))
+             (assert-error (stable-sort '(1 2 3) (lambda (x y) (error "bad comparison"))))
+             (assert-error (sort 5 <))
+             (assert-error (sort '(1 2) 5))))
@@ -4,6 +4,30 @@
   `(+ ,x ,@y))
 
 
+(defmacro (swap! a b)
+  (let ((tmp (gensym 'tmp)))
+    `(let ((,tmp ,a))
+       (set! ,a ,b)
+       (set! ,b ,tmp))))
+
+(defmacro (my-when c . body)
+  `(if ,c (begin ,@body)))
+
+(defmacro (my-unless c . body)
+  `(my-when (not ,c) ,@body))
+
+(defmacro (rest-when test &rest body)
+  `(if ,test (begin ,@body) #f))
+
+(defmacro (body-unless test &body body)
+  `(if ,test #f (begin ,@body)))
+
+(defmacro (capturing-swap! a b)
+  `(let ((tmp ,a))
+     (set! ,a ,b)
+     (set! ,b tmp)))
+
+
 (context "macro"
 
          ()
@@ -28,6 +52,37 @@
              (assert-eq `(a ,@(list 1 2 3) b)
                         '(a 1 2 3 b)))
 
+         (it macroexpand-1
+             (assert-eq (macroexpand-1 '(my-unless x (f) (g)))
+                        '(my-when (not x) (f) (g)))
+             (assert-eq (macroexpand-1 '(+ 1 2)) '(+ 1 2))
+             (assert-eq (macroexpand-1 'x) 'x)
+             (assert-error (macroexpand-1 '(my-when))))
+
+         (it macroexpand
+             (assert-eq (macroexpand '(my-unless x (f) (g)))
+                        '(if (not x) (begin (f) (g))))
+             (assert-eq (macroexpand '(my-when x (my-unless y z)))
+                        '(if x (begin (my-unless y z))))
+             (assert-eq (macroexpand '(+ 1 2)) '(+ 1 2))
+             (assert-eq (macroexpand 5) 5))
+
+         (it rest-parameters
+             (assert-eq (rest-when #t 1 2 3) 3)
+             (assert-false (rest-when #f (error "not evaluated")))
+             (assert-eq (body-unless #f 'a 'b) 'b)
+             (assert-false (body-unless #t 'a))
+             (assert-eq (macroexpand-1 '(rest-when x (f) (g)))
+                        '(if x (begin (f) (g)) #f))
+             (assert-eq (macroexpand-1 '(rest-when x))
+                        '(if x (begin) #f))
+             (assert-eq ((lambda (a &rest r) r) 1 2 3) '(2 3))
+             (assert-error (rest-when))
+             (defmacro (bad-rest a &rest) a)
+             (assert-error (bad-rest 1))
+             (defmacro (bad-rest2 &rest a b) a)
+             (assert-error (bad-rest2 1)))
+
          (it nested-unquote-splicing
              (assert-eq `(a ,@(list 1 2 3) `(list ,@(list a b c)))
                         '(a 1 2 3 `(list ,@(list a b c)))))
@@ -50,6 +105,16 @@
              (assert-eq  `(a `(b ,(+ 1 2) ,(foo ,(+ 1 3) d) e) f) 
                          '(a `(b ,(+ 1 2) ,(foo 4 d) e) f)))
 
+         (it gensym-avoids-capture
+             (let ((tmp 1)
+                   (other 2))
+               (swap! tmp other)
+               (assert-eq (list tmp other) '(2 1)))
+             (let ((tmp 1)
+                   (other 2))
+               (capturing-swap! tmp other)
+               (assert-eq (list tmp other) '(1 2))))
+
          (it defmacro-errors
              (assert-error (defmacro "x" 1))
              (assert-error (defmacro ("x") 1)))
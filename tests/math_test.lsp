@@ -24,6 +24,33 @@
              (assert-eq (modulo 7 5)
                         2))
 
+         (it integer-division
+             (assert-eq (quotient 7 2) 3)
+             (assert-eq (quotient -7 2) -3)
+             (assert-eq (quotient 7 -2) -3)
+             (assert-eq (quotient -7 -2) 3)
+             (assert-eq (remainder 7 2) 1)
+             (assert-eq (remainder -7 2) -1)
+             (assert-eq (remainder 7 -2) 1)
+             (assert-eq (remainder -7 -2) -1)
+             (assert-eq (rem -7 2) -1)
+             (assert-eq (% -7 2) -1)
+             (assert-eq (modulo 7 2) 1)
+             (assert-eq (modulo -7 2) 1)
+             (assert-eq (modulo 7 -2) -1)
+             (assert-eq (modulo -7 -2) -1)
+             (assert-eq (mod -7 2) 1)
+             (assert-eq (mod 6 -3) 0)
+             (assert-eq (mod -6 3) 0))
+
+         (it integer-division-errors
+             (assert-error (quotient 7 0))
+             (assert-error (remainder 7 0))
+             (assert-error (modulo 7 0))
+             (assert-error (quotient 7.0 2))
+             (assert-error (modulo 7 2.0))
+             (assert-error (rem 'a 2)))
+
          (it subtraction-going-negative
              (assert-eq (- 5 9)
                         -4))
@@ -105,6 +132,16 @@
              (assert-eq (floor 3)
                         3.0))
 
+         (it floor/
+             (assert-eq (call-with-values (lambda () (floor/ 7 2)) list)
+                        '(3 1))
+             (assert-eq (call-with-values (lambda () (floor/ -7 2)) list)
+                        '(-4 1))
+             (assert-eq (call-with-values (lambda () (floor/ 7 -2)) list)
+                        '(-4 -1))
+             (assert-eq (call-with-values (lambda () (floor/ 6 3)) list)
+                        '(2 0)))
+
          (it ceiling
              (assert-eq (ceiling 3.4)
                         4.0)
@@ -113,12 +150,62 @@
              (assert-eq (ceiling 3)
                         3.0))
 
+         (it round
+             (assert-eq (round 3.4)
+                        3.0)
+             (assert-eq (round 3.6)
+                        4.0)
+             (assert-eq (round -3.6)
+                        -4.0)
+             (assert-eq (round 2.5)
+                        2.0)
+             (assert-eq (round 3.5)
+                        4.0)
+             (assert-eq (round -2.5)
+                        -2.0)
+             (assert-eq (round 3)
+                        3.0)
+             (assert-eq (ceil 3.4)
+                        4.0))
+
+         (it transcendental
+             (assert-eq (sqrt 16)
+                        4.0)
+             (assert-eq (sqrt 0)
+                        0.0)
+             (assert-eq (log 1)
+                        0.0)
+             (assert-eq (exp 0)
+                        1.0)
+             (assert-eq (log2 8)
+                        3.0)
+             (assert-eq (sin 0)
+                        0.0)
+             (assert-eq (cos 0)
+                        1.0)
+             (assert-eq (pow 2 10)
+                        1024)
+             (assert-eq (pow 2.0 0.5)
+                        (sqrt 2)))
+
+         (it domain-errors
+             (assert-error (sqrt -1))
+             (assert-error (log 0))
+             (assert-error (log -1.5))
+             (assert-error (log10 0))
+             (assert-error (log2 -2))
+             (assert-error (log1p -1))
+             (assert-error (round 'r))
+             (assert-error (sqrt "4")))
+
          (it general-math-errors
              (assert-error (/ 3 0))
              (assert-error (% 3.5 6))
              (assert-error (min '(1 d)))
              (assert-error (max 5.4 i))
              (assert-error (floor 'd))
+             (assert-error (floor/ 7 0))
+             (assert-error (floor/ 7.5 2))
              (assert-error (ceiling 'd))
              (assert-error (abs "hi"))
              (assert-error (zero? 'zero))
@@ -128,3 +215,78 @@
              (assert-error (odd? 'r))
              (assert-error (sign 's)))
 )
+
+(context "number->formatted-string"
+
+         ()
+
+         (it "formats plainly without options"
+             (assert-eq (number->formatted-string 1234) "1234")
+             (assert-eq (number->formatted-string -1234 '()) "-1234")
+             (assert-eq (number->formatted-string 2.5) "2.5"))
+
+         (it "supports a base"
+             (assert-eq (number->formatted-string 255 '((base . 16))) "ff")
+             (assert-eq (number->formatted-string 5 '((base . 2))) "101")
+             (assert-eq (number->formatted-string 8 '((base . 8))) "10")
+             (assert-error (number->formatted-string 5 '((base . 3))))
+             (assert-error (number->formatted-string 5.5 '((base . 16)))))
+
+         (it "supports a minimum width"
+             (assert-eq (number->formatted-string 42 '((width . 5))) "   42")
+             (assert-eq (number->formatted-string -42 '((width . 5))) "  -42")
+             (assert-eq (number->formatted-string 123456 '((width . 3))) "123456")
+             (assert-error (number->formatted-string 5 '((width . -1)))))
+
+         (it "supports a pad character"
+             (assert-eq (number->formatted-string 255 '((base . 16) (width . 4) (pad . "0"))) "00ff")
+             (assert-eq (number->formatted-string -42 '((width . 5) (pad . "0"))) "-0042")
+             (assert-eq (number->formatted-string 42 '((width . 5) (pad . "*"))) "***42")
+             (assert-error (number->formatted-string 5 '((pad . "ab")))))
+
+         (it "supports digit grouping"
+             (assert-eq (number->formatted-string 1234567 '((grouping . ","))) "1,234,567")
+             (assert-eq (number->formatted-string -123456 '((grouping . ","))) "-123,456")
+             (assert-eq (number->formatted-string 123 '((grouping . ","))) "123")
+             (assert-eq (number->formatted-string 1234.5 '((grouping . ","))) "1,234.5")
+             (assert-eq (number->formatted-string 65535 '((base . 2) (grouping . "_"))) "1111_1111_1111_1111")
+             (assert-error (number->formatted-string 5 '((grouping . 3)))))
+
+         (it "rejects non-numbers"
+             (assert-error (number->formatted-string "5" '()))
+             (assert-error (number->formatted-string 5 5))))
+
+(context "digit conversion"
+
+         ()
+
+         (it "converts characters to digits"
+             (assert-eq (char->digit "7") 7)
+             (assert-eq (char->digit "1" 2) 1)
+             (assert-eq (char->digit "7" 8) 7)
+             (assert-eq (char->digit "a" 16) 10)
+             (assert-eq (char->digit "F" 16) 15)
+             (assert-eq (char->digit "z" 36) 35))
+
+         (it "returns false for characters that aren't digits in the base"
+             (assert-false (char->digit "2" 2))
+             (assert-false (char->digit "8" 8))
+             (assert-false (char->digit "a"))
+             (assert-false (char->digit "g" 16))
+             (assert-false (char->digit "-" 36)))
+
+         (it "converts digits to characters"
+             (assert-eq (digit->char 7) "7")
+             (assert-eq (digit->char 1 2) "1")
+             (assert-eq (digit->char 10 16) "a")
+             (assert-eq (digit->char 35 36) "z"))
+
+         (it "rejects bad arguments"
+             (assert-error (digit->char 10))
+             (assert-error (digit->char 2 2))
+             (assert-error (digit->char -1 16))
+             (assert-error (digit->char "a" 16))
+             (assert-error (char->digit "ab" 16))
+             (assert-error (char->digit 5 10))
+             (assert-error (char->digit "1" 1))
+             (assert-error (char->digit "1" 37))))
@@ -0,0 +1,39 @@
+;;; -*- mode: Scheme -*-
+
+(context "memoize"
+
+         ((define calls 0)
+          (define (slow-square x)
+            (set! calls (+ calls 1))
+            (* x x))
+          (define fast-square (memoize slow-square))
+          (define fib (memoize (lambda (n)
+                                 (if (< n 2)
+                                     n
+                                     (+ (fib (- n 1)) (fib (- n 2))))))))
+
+         (it "applies the function only on a cache miss"
+             (set! calls 0)
+             (assert-eq (fast-square 4) 16)
+             (assert-eq (fast-square 4) 16)
+             (assert-eq calls 1)
+             (assert-eq (fast-square 5) 25)
+             (assert-eq calls 2))
+
+         (it "caches recursive calls"
+             (assert-eq (fib 80) 23416728348467685))
+
+         (it "forgets its results when cleared"
+             (set! calls 0)
+             (fast-square 6)
+             (clear-memoize fast-square)
+             (assert-eq (fast-square 6) 36)
+             (assert-eq calls 2))
+
+         (it "passes on errors without caching them"
+             (assert-error (fast-square "x"))
+             (assert-error (fast-square 1 2)))
+
+         (it "requires functions"
+             (assert-error (memoize 5))
+             (assert-error (clear-memoize slow-square))))
@@ -8,4 +8,20 @@
                    (assert-nil '())
                    (assert-not-nil '(()))
                    (assert-not-nil '(()()))
-                   (assert-nil ())))
+                   (assert-nil ()))
+
+         (it "prints the empty list as ()"
+             (assert-eq (str '()) "()")
+             (assert-eq (str nil) "()")
+             (assert-eq (str (list)) "()"))
+
+         (it "prints a missing value as the empty list"
+             (assert-eq (str (if #f #f)) "()")
+             (assert-nil (if #f #f)))
+
+         (it "keeps false distinct from the empty list"
+             (assert-eq (str #f) "#f")
+             (assert-eq (str #t) "#t")
+             (assert-false (eq? '() #f))
+             (assert-true (not '()))
+             (assert-true (not #f))))
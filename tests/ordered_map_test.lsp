@@ -0,0 +1,56 @@
+;;; -*- mode: Scheme -*-
+
+(context "ordered maps"
+
+         ((define m (make-ordered-map))
+          (ordered-map-set! m 'c 3)
+          (ordered-map-set! m 'a 1)
+          (ordered-map-set! m 'b 2))
+
+         (it "can be recognized"
+             (assert-true (ordered-map? m))
+             (assert-false (ordered-map? (make-hash-table)))
+             (assert-false (ordered-map? '((a . 1)))))
+
+         (it "looks up values"
+             (assert-eq (ordered-map-ref m 'a) 1)
+             (assert-nil (ordered-map-ref m 'z))
+             (assert-eq (ordered-map-ref m 'z 0) 0)
+             (assert-true (ordered-map-has-key? m 'b))
+             (assert-false (ordered-map-has-key? m 'z))
+             (assert-eq (ordered-map-size m) 3))
+
+         (it "iterates in insertion order"
+             (assert-eq (ordered-map-keys m) '(c a b))
+             (assert-eq (ordered-map-values m) '(3 1 2))
+             (assert-eq (ordered-map->alist m) '((c . 3) (a . 1) (b . 2))))
+
+         (it "keeps the position of a key across updates"
+             (ordered-map-set! m 'a 10)
+             (assert-eq (ordered-map-keys m) '(c a b))
+             (assert-eq (ordered-map-ref m 'a) 10))
+
+         (it "moves a removed and re-added key to the end"
+             (assert-true (ordered-map-remove! m 'c))
+             (assert-false (ordered-map-remove! m 'c))
+             (assert-eq (ordered-map-keys m) '(a b))
+             (ordered-map-set! m 'c 30)
+             (assert-eq (ordered-map-keys m) '(a b c)))
+
+         (it "finds keys that are equal?"
+             (let ((numbers (make-ordered-map)))
+               (ordered-map-set! numbers 1 'one)
+               (ordered-map-set! numbers 2 'two)
+               (ordered-map-set! numbers 1.0 'uno)
+               (assert-eq (ordered-map-size numbers) 2)
+               (assert-eq (ordered-map-keys numbers) '(1 2))
+               (assert-eq (ordered-map-ref numbers 1) 'uno)
+               (assert-true (ordered-map-remove! numbers 1.0))
+               (assert-eq (ordered-map-keys numbers) '(2))))
+
+         (it "converts to json in insertion order"
+             (assert-eq (lisp->json m) "{\"c\":3,\"a\":1,\"b\":2}"))
+
+         (it "rejects things that aren't ordered maps"
+             (assert-error (ordered-map-ref 5 'a))
+             (assert-error (ordered-map-set! (make-hash-table) 'a 1))))
@@ -0,0 +1,35 @@
+;;; -*- mode: Scheme -*-
+
+(context "with-output-to-string"
+
+         ((define (greet name) (write-string "hello ") (write-string name)))
+
+         (it "captures what the body writes"
+             (assert-eq (with-output-to-string (write-string "hello")) "hello")
+             (assert-eq (with-output-to-string
+                         (write-line "a" 1)
+                         (write '(b "c"))
+                         (newline))
+                        "a1\n(b \"c\")\n")
+             (assert-eq (with-output-to-string (format #t "~a-~a" 1 2)) "1-2"))
+
+         (it "captures what functions called from the body write"
+             (assert-eq (with-output-to-string (greet "there")) "hello there")
+             (assert-eq (with-output-to-string (for-each greet '("a" "b"))) "hello ahello b"))
+
+         (it "returns an empty string when nothing is written"
+             (assert-eq (with-output-to-string (+ 1 2)) ""))
+
+         (it "nests"
+             (assert-eq (with-output-to-string
+                         (write-string "outer ")
+                         (write-string (string-upcase (with-output-to-string (write-string "inner"))))
+                         (write-string " done"))
+                        "outer INNER done"))
+
+         (it "captures time-it's report"
+             (assert-true (string-prefix? "elapsed: " (with-output-to-string (time-it (+ 1 2))))))
+
+         (it "propagates errors and restores the output"
+             (assert-error (with-output-to-string (write-string "x") (error "failed")))
+             (assert-eq (with-output-to-string (write-string "after")) "after")))
@@ -18,6 +18,29 @@
              (assert-eq (reduce list '() '(1 2 3 4))
                         '(((1 2) 3) 4)))
 
+         (it fold-left
+             (assert-eq (fold-left + 0 '(1 2 3)) 6)
+             (assert-eq (fold-left + 10 '(1)) 11)
+             (assert-eq (fold-left + 10 '()) 10)
+             (assert-eq (fold-left (lambda (l i) (cons i l)) '() '(1 2 3))
+                        '(3 2 1))
+             (assert-eq (fold-left list 0 '(1 2 3))
+                        '(((0 1) 2) 3)))
+
+         (it fold-right
+             (assert-eq (fold-right + 0 '(1 2 3)) 6)
+             (assert-eq (fold-right + 10 '()) 10)
+             (assert-eq (fold-right cons '() '(1 2 3))
+                        '(1 2 3))
+             (assert-eq (fold-right list 0 '(1 2 3))
+                        '(1 (2 (3 0)))))
+
+         (it fold-errors
+             (assert-error (fold-left 1 0 '(1 2)))
+             (assert-error (fold-right + 0 1))
+             (assert-error (fold-left (lambda (acc i) (error "bad")) 0 '(1)))
+             (assert-error (fold-right (lambda (i acc) (error "bad")) 0 '(1))))
+
          (it reduce-errors
              (assert-error (reduce r r '(1 2))) ;initial arg must be a function
              (assert-error (reduce + 0 1))) ;last/3rd arg must be a list
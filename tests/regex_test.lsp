@@ -0,0 +1,33 @@
+;;; -*- mode: Scheme -*-
+
+(context "regular expressions"
+
+         ()
+
+         (it "matches"
+             (assert-true (regex-match? "^a+b$" "aaab"))
+             (assert-false (regex-match? "^a+b$" "aaac")))
+
+         (it "finds the first match"
+             (assert-eq (regex-find "[0-9]+" "abc 123 def 45") "123")
+             (assert-nil (regex-find "[0-9]+" "abc")))
+
+         (it "finds capture groups"
+             (assert-eq (regex-find-groups "(\\w+)@(\\w+)\\.com" "mail bob@example.com now")
+                        '("bob@example.com" "bob" "example"))
+             (assert-eq (regex-find-groups "a(x)?b" "ab") '("ab" ()))
+             (assert-nil (regex-find-groups "(z)" "abc")))
+
+         (it "finds all matches"
+             (assert-eq (regex-find-all "[0-9]+" "1 22 333") '("1" "22" "333"))
+             (assert-eq (regex-find-all "[0-9]+" "none") '()))
+
+         (it "replaces matches"
+             (assert-eq (regex-replace "[0-9]+" "#" "a1b22c") "a#b#c")
+             (assert-eq (regex-replace "(\\w+)=(\\w+)" "$2=$1" "a=b c=d") "b=a d=c"))
+
+         (it "errors on bad patterns and arguments"
+             (assert-error (regex-match? "(" "x"))
+             (assert-error (regex-find 1 "x"))
+             (assert-error (regex-find-all "x" 'x))
+             (assert-error (regex-replace "x" 1 "x"))))
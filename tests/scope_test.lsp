@@ -21,3 +21,47 @@
                         7)
              (assert-eq ((foo 10) 7)
                         17)))
+
+(context "closures"
+
+         (
+          (define (make-counter)
+            (let ((n 0))
+              (lambda ()
+                (set! n (+ n 1))
+                n)))
+          (define (make-shared-counter)
+            (let ((n 0))
+              (list (lambda () (set! n (+ n 1)) n)
+                    (lambda () n))))
+          )
+
+         (it "keep their captured state between calls"
+             (let ((counter (make-counter)))
+               (counter)
+               (counter)
+               (assert-eq (counter) 3)))
+
+         (it "don't share state with independent closures"
+             (let ((a (make-counter))
+                   (b (make-counter)))
+               (a)
+               (a)
+               (assert-eq (b) 1)
+               (assert-eq (a) 3)
+               (assert-eq (b) 2)))
+
+         (it "see changes made to a captured binding after they are created"
+             (let* ((pair (make-shared-counter))
+                    (increment (car pair))
+                    (current (cadr pair)))
+               (assert-eq (current) 0)
+               (increment)
+               (increment)
+               (assert-eq (current) 2)))
+
+         (it "see changes made by the enclosing scope"
+             (let* ((x 1)
+                    (get-x (lambda () x)))
+               (set! x 5)
+               (assert-eq (get-x) 5))))
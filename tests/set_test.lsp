@@ -45,4 +45,25 @@
                           (set-nth! l 3 1)
                           (nth l 3))
                         1))
+
+         (it list-set!
+             (assert-eq (let ((l (list 'a 'b 'c)))
+                          (list-set! l 0 1)
+                          (list-set! l 2 3)
+                          l)
+                        '(1 b 3)))
+
+         (it list-set!-mutates-shared-structure
+             (assert-eq (let* ((l (list 'a 'b 'c))
+                               (alias l))
+                          (list-set! l 1 'x)
+                          alias)
+                        '(a x c)))
+
+         (it list-set!-errors
+             (assert-error (list-set! (list 1 2) 2 'x))
+             (assert-error (list-set! (list 1 2) -1 'x))
+             (assert-error (list-set! '() 0 'x))
+             (assert-error (list-set! (list 1 2) 'a 'x))
+             (assert-error (list-set! 5 0 'x)))
 )
@@ -0,0 +1,83 @@
+;;; -*- mode: Scheme -*-
+
+(define (integers-from n)
+  (stream-cons n (integers-from (+ n 1))))
+
+(define naturals (integers-from 0))
+
+(context "promises"
+
+         ((define count 0))
+
+         (it "delays evaluation until forced"
+             (set! count 0)
+             (let ((p (delay (begin (set! count (+ count 1)) 'done))))
+               (assert-true (promise? p))
+               (assert-eq count 0)
+               (assert-eq (force p) 'done)
+               (assert-eq count 1)))
+
+         (it "only evaluates once"
+             (set! count 0)
+             (let ((p (delay (begin (set! count (+ count 1)) count))))
+               (force p)
+               (force p)
+               (assert-eq (force p) 1)))
+
+         (it "forces non-promises to themselves"
+             (assert-eq (force 5) 5)
+             (assert-false (promise? 5)))
+
+         (it "can be forced from its own thunk"
+             (set! count 0)
+             (define x 5)
+             (define p (delay (begin (set! count (+ count 1))
+                                     (if (> count x)
+                                         count
+                                         (force p)))))
+             (assert-eq (force p) 6)
+             (set! x 10)
+             (assert-eq (force p) 6)))
+
+(context "streams"
+
+         ()
+
+         (it "takes a prefix of an infinite stream"
+             (assert-eq (stream-take naturals 5) '(0 1 2 3 4))
+             (assert-eq (stream-take naturals 0) '()))
+
+         (it "accesses elements"
+             (assert-eq (stream-car naturals) 0)
+             (assert-eq (stream-car (stream-cdr (stream-cdr naturals))) 2))
+
+         (it "maps lazily over an infinite stream"
+             (assert-eq (stream-take (stream-map (lambda (x) (* x x)) naturals) 4)
+                        '(0 1 4 9)))
+
+         (it "maps over several streams together"
+             (assert-eq (stream-take (stream-map + naturals (stream-cdr naturals)) 4)
+                        '(1 3 5 7))
+             (assert-eq (stream-take (stream-map list naturals (stream-cons 'a (stream-cons 'b the-empty-stream))) 10)
+                        '((0 a) (1 b))))
+
+         (it "does not compute elements that are not taken"
+             (define computed 0)
+             (define (counted-from n)
+               (stream-cons (begin (set! computed (+ computed 1)) n)
+                            (counted-from (+ n 1))))
+             (stream-take (counted-from 0) 3)
+             (assert-eq computed 3))
+
+         (it "handles finite streams"
+             (let ((s (stream-cons 1 (stream-cons 2 the-empty-stream))))
+               (assert-eq (stream-take s 10) '(1 2))
+               (assert-true (stream-pair? s))
+               (assert-true (stream-null? (stream-cdr (stream-cdr s))))
+               (assert-eq (stream-take (stream-map succ s) 10) '(2 3))))
+
+         (it "rejects non-streams"
+             (assert-error (stream-car '(1 2)))
+             (assert-error (stream-cdr 5))
+             (assert-error (stream-take naturals -1))
+             (assert-error (stream-map 5 naturals))))
@@ -59,9 +59,32 @@
                         '("1" "2"))
              (assert-eq (string-split "one,two" ",")
                         '("one" "two"))
+             (assert-eq (string-split "a, b, c" ", ")
+                        '("a" "b" "c"))
+             (assert-eq (string-split "abc" "")
+                        '("a" "b" "c"))
+             (assert-eq (string-split "abc" "-")
+                        '("abc"))
+             (assert-eq (string-split "" ",")
+                        '(""))
+             (assert-eq (string-split "a,,b" ",")
+                        '("a" "" "b"))
              (assert-error (string-split 3 ""))
              (assert-error (string-split "" 3)))
 
+         (it string-join
+             (assert-eq (string-join '("a" "b" "c") ", ")
+                        "a, b, c")
+             (assert-eq (string-join '("a" "b"))
+                        "ab")
+             (assert-eq (string-join '() ",")
+                        "")
+             (assert-eq (string-join (string-split "1-2-3" "-") "+")
+                        "1+2+3")
+             (assert-error (string-join '("a" 1) ","))
+             (assert-error (string-join "ab" ","))
+             (assert-error (string-join '("a") 1)))
+
          (it string-trim
              (assert-eq (string-trim "  hello ")
                         "hello")
@@ -144,6 +167,22 @@
              (assert-error (string-capitalize! 6)))
 
 
+         (it string-reverse
+             (assert-eq (string-reverse "hello")
+                        "olleh")
+             (assert-eq (string-reverse "héllo wörld")
+                        "dlröw olléh")
+             (assert-eq (string-reverse "日本語")
+                        "語本日")
+             (assert-eq (string-reverse "")
+                        "")
+             (assert-eq (let* ((s "abc")
+                               (r (string-reverse s)))
+                          s)
+                        "abc")
+             (assert-error (string-reverse 5)))
+
+
          (it string-length
              (assert-eq (string-length "")
                         0)
@@ -227,4 +266,17 @@
              (assert-false (string>=? "a" "b"))
              (assert-true (string>=? "a" "a"))
              (assert-true (string>=? "a" "A"))
-             (assert-true (string-ci>=? "a" "A"))))
+             (assert-true (string-ci>=? "a" "A")))
+
+         (it string-append
+             (assert-eq (string-append "foo" "bar" "baz") "foobarbaz")
+             (assert-eq (string-append) "")
+             (assert-eq (string-append "point" '-x) "point-x")
+             (assert-eq (string-concat "a" "b") "ab")
+             (assert-error (string-append "a" 1)))
+
+         (it symbol-append
+             (assert-eq (symbol-append 'point '-x) 'point-x)
+             (assert-eq (symbol-append 'make- "point") 'make-point)
+             (assert-true (symbol? (symbol-append 'a)))
+             (assert-error (symbol-append 'a 1))))
@@ -12,9 +12,63 @@
              (assert-eq (apply + '(1 2)) 3)
              (assert-eq (apply + 1 2 '(3)) 6)
              (assert-error (apply 5 '(1 2))) ;1st arg must be a function
-             (assert-error (apply + 1 2))) ;last are must be a list
+             (assert-error (apply + 1 2)) ;last are must be a list
+             (assert-error (apply +)) ;needs an argument list
+             (assert-eq (apply (lambda (a b c) (list c b a)) '(1 2 3)) '(3 2 1))
+             (assert-eq (apply (lambda (a . rest) rest) 1 '(2 3)) '(2 3))
+             (assert-eq (apply list '()) '())
+             (assert-error (apply (lambda (a b) a) '(1)))) ;arity errors propagate
+
+         (it time-it
+             (assert-eq (time-it (+ 1 2)) 3)
+             (assert-eq (time-it (define time-it-x 4) (* time-it-x 2)) 8)
+             (assert-error (time-it (error "failed"))))
+
+         (it error
+             (assert-error (error "failed"))
+             (assert-eq (guard (e ((string? e) (regex-match? "bad value 42 for x$" e)))
+                          (error "bad value ~a for ~a" 42 'x))
+                        #t)
+             (assert-eq (guard (e ((string? e) (regex-match? "Evaling \\(error 'oops\\)\\. oops$" e)))
+                          (error 'oops))
+                        #t)
+             (assert-eq (guard (e ((string? e) (regex-match? "50~ off~a$" e)))
+                          (error "50~ off~a"))
+                        #t)
+             (assert-error (error 'oops 1))
+             (assert-error (error "~a and ~a" 1)))
+
+         (it assert
+             (assert-eq (assert (+ 1 2)) 3)
+             (assert-true (assert (> 2 1) "never shown"))
+             (assert-error (assert #f))
+             (assert-error (assert '()))
+             (assert-eq (guard (e ((string? e) (regex-match? "Assertion failed: \\(> 1 2\\)$" e)))
+                          (assert (> 1 2)))
+                        #t)
+             (assert-eq (guard (e ((string? e) (regex-match? "Assertion failed: \\(> 1 x\\): x is 2$" e)))
+                          (let ((x 2))
+                            (assert (> 1 x) (format #f "x is ~a" x))))
+                        #t))
 
          (it eval
              (assert-eq (+ 1 2) 3)
              (assert-error (5 1 2))
              (assert-error ('list 1 2))))
+
+(context "allocations-during"
+
+         ()
+
+         (it "counts nothing for a function that doesn't cons"
+             (assert-eq (allocations-during (lambda () 42)) 0))
+
+         (it "counts the cons cells a function allocates"
+             (assert-true (>= (allocations-during (lambda () (make-list 100 0))) 100)))
+
+         (it "distinguishes allocating and non-allocating functions"
+             (assert-true (> (allocations-during (lambda () (make-list 50 0)))
+                             (allocations-during (lambda () 42)))))
+
+         (it "requires a function"
+             (assert-error (allocations-during 5))))
@@ -0,0 +1,120 @@
+;;; -*- mode: Scheme -*-
+
+(context "tail-call?"
+
+         ()
+
+         (it "treats the form itself as a tail position"
+             (assert-true (tail-call? '(loop x) '(loop x)))
+             (assert-false (tail-call? '(+ 1 (loop x)) '(loop x))))
+
+         (it "handles if"
+             (assert-true (tail-call? '(if (done? x) x (loop x)) '(loop x)))
+             (assert-true (tail-call? '(if (done? x) (loop x) x) '(loop x)))
+             (assert-false (tail-call? '(if (loop x) 1 2) '(loop x))))
+
+         (it "handles begin"
+             (assert-true (tail-call? '(begin (display x) (loop x)) '(loop x)))
+             (assert-false (tail-call? '(begin (loop x) (display x)) '(loop x))))
+
+         (it "handles let forms"
+             (assert-true (tail-call? '(let ((y 1)) (display y) (loop x)) '(loop x)))
+             (assert-false (tail-call? '(let ((y (loop x))) y) '(loop x)))
+             (assert-true (tail-call? '(let* ((y 1)) (loop x)) '(loop x)))
+             (assert-true (tail-call? '(let iter ((y 1)) (loop x)) '(loop x))))
+
+         (it "handles cond"
+             (assert-true (tail-call? '(cond ((zero? x) 0)
+                                             (else (display x) (loop x)))
+                                      '(loop x)))
+             (assert-false (tail-call? '(cond ((loop x) 0)
+                                              (else 1))
+                                       '(loop x)))
+             (assert-false (tail-call? '(cond ((loop x)) (else 1)) '(loop x))))
+
+         (it "handles when, unless, and, and or"
+             (assert-true (tail-call? '(when (ready? x) (loop x)) '(loop x)))
+             (assert-true (tail-call? '(unless (ready? x) (loop x)) '(loop x)))
+             (assert-true (tail-call? '(and (ready? x) (loop x)) '(loop x)))
+             (assert-false (tail-call? '(or (loop x) #f) '(loop x))))
+
+         (it "handles case and do"
+             (assert-true (tail-call? '(case x ((1) (loop x)) (else 0)) '(loop x)))
+             (assert-true (tail-call? '(do ((i 0 (+ i 1))) ((> i 3) (loop x))) '(loop x)))
+             (assert-false (tail-call? '(case x ((1) (loop x) (fallthrough)) (else 0)) '(loop x)))
+             (assert-false (tail-call? '(do ((i 0 (+ i 1))) ((> i 3) 0) (loop x)) '(loop x))))
+
+         (it "does not look into nested lambdas"
+             (assert-false (tail-call? '(lambda () (loop x)) '(loop x)))))
+
+(define (count-down n)
+  (if (== n 0)
+      'done
+      (count-down (- n 1))))
+
+(define (count-with-cond n acc)
+  (cond ((== n 0) acc)
+        (else (let ((next (- n 1)))
+                (count-with-cond next (+ acc 1))))))
+
+(define (ping n)
+  (when (> n 0)
+    (pong (- n 1))))
+
+(define (pong n)
+  (if (== n 0)
+      'pong
+      (begin
+        (ping (- n 1)))))
+
+(define (count-with-and n)
+  (and (>= n 0)
+       (or (== n 0)
+           (count-with-and (- n 1)))))
+
+(define (count-with-case n)
+  (case (if (== n 0) 'zero 'more)
+    ((zero) 'done)
+    (else (count-with-case (- n 1)))))
+
+(define (count-with-do n)
+  (do ((i 0 (+ i 1)))
+      ((== i 1) (if (== n 0) 'done (count-with-do (- n 1))))))
+
+(define (count-with-arrow n)
+  (cond ((== n 0) 'done)
+        ((- n 1) => count-with-arrow)))
+
+(define (count-with-named-let n)
+  (if (== n 0)
+      'done
+      (let iter ((m (- n 1)))
+        (count-with-named-let m))))
+
+(context "tail-call optimization"
+
+         ()
+
+         (it "runs deep self recursion in constant stack"
+             (assert-eq (count-down 1000000) 'done))
+
+         (it "passes tail position through cond, else, and let"
+             (assert-eq (count-with-cond 100000 0) 100000))
+
+         (it "passes tail position through and, or, case, do, and named let"
+             (assert-true (count-with-and 20000))
+             (assert-eq (count-with-case 20000) 'done)
+             (assert-eq (count-with-do 20000) 'done)
+             (assert-eq (count-with-named-let 20000) 'done))
+
+         (it "makes the call of a cond => clause a tail call"
+             (assert-eq (count-with-arrow 20000) 'done)
+             (assert-eq (cond (4 => (lambda (x) (* x x)))) 16)
+             (assert-eq (cond ('(1 2) => car)) 1))
+
+         (it "handles mutual recursion"
+             (assert-eq (pong 100000) 'pong)
+             (assert-eq (ping 100001) 'pong))
+
+         (it "still reports errors from tail calls"
+             (assert-error (count-down 'x))))
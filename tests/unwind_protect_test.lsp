@@ -0,0 +1,36 @@
+;;; -*- mode: Scheme -*-
+
+(context "unwind-protect"
+
+         ((define cleaned-up '()))
+
+         (it "returns the protected form's value after cleaning up"
+             (set! cleaned-up '())
+             (assert-eq (unwind-protect (+ 1 2)
+                          (set! cleaned-up (cons 'a cleaned-up))
+                          (set! cleaned-up (cons 'b cleaned-up)))
+                        3)
+             (assert-eq cleaned-up '(b a)))
+
+         (it "cleans up when the protected form raises"
+             (set! cleaned-up '())
+             (assert-error (unwind-protect (error "failed")
+                             (set! cleaned-up #t)))
+             (assert-true cleaned-up))
+
+         (it "keeps the original error"
+             (assert-eq (guard (e (#t e))
+                          (unwind-protect (raise 'first)
+                            (set! cleaned-up 'done)))
+                        'first))
+
+         (it "cleans up when the protected form throws"
+             (set! cleaned-up '())
+             (assert-eq (catch 'out
+                          (unwind-protect (throw 'out 5)
+                            (set! cleaned-up #t)))
+                        5)
+             (assert-true cleaned-up))
+
+         (it "reports a cleanup error when the protected form succeeded"
+             (assert-error (unwind-protect 1 (error "cleanup failed")))))
@@ -0,0 +1,75 @@
+;;; -*- mode: Scheme -*-
+
+(context "multiple values"
+
+         ()
+
+         (it "passes values to a consumer"
+             (assert-eq (call-with-values (lambda () (values 1 2)) +) 3)
+             (assert-eq (call-with-values (lambda () (values 1 2 3)) list) '(1 2 3)))
+
+         (it "treats a single value as itself"
+             (assert-eq (values 5) 5)
+             (assert-eq (call-with-values (lambda () 5) list) '(5)))
+
+         (it "supports zero values"
+             (assert-eq (call-with-values (lambda () (values)) list) '()))
+
+         (it "converts a list to values"
+             (assert-eq (call-with-values (lambda () (list->values '(1 2 3))) list)
+                        '(1 2 3))
+             (assert-eq (call-with-values (lambda () (list->values '())) list)
+                        '())
+             (assert-eq (call-with-values (lambda () (list->values '(7))) list)
+                        '(7)))
+
+         (it "rejects bad arguments"
+             (assert-error (list->values 5))
+             (assert-error (call-with-values 5 list))
+             (assert-error (call-with-values (lambda () 1) 5))))
+
+(define-values (vq vr) (floor/ 7 2))
+
+(context "let-values"
+
+         ()
+
+         (it "binds two values"
+             (assert-eq (let-values (((q r) (floor/ 7 2)))
+                          (list q r))
+                        '(3 1)))
+
+         (it "binds several producers"
+             (assert-eq (let-values (((a b) (values 1 2))
+                                     ((c) (values 3))
+                                     (() (values)))
+                          (list a b c))
+                        '(1 2 3)))
+
+         (it "evaluates producers in the enclosing environment"
+             (assert-eq (let ((a 10))
+                          (let-values (((a b) (values 1 2))
+                                       ((c) (values a)))
+                            c))
+                        10))
+
+         (it "errors on an arity mismatch"
+             (assert-error (let-values (((a b) (values 1 2 3))) a))
+             (assert-error (let-values (((a b c) (floor/ 7 2))) a))
+             (assert-error (let-values (((a b) 5)) a)))
+
+         (it "rejects bad bindings"
+             (assert-error (let-values ((a (values 1))) a))
+             (assert-error (let-values (((1 b) (values 1 2))) b))))
+
+(context "define-values"
+
+         ()
+
+         (it "binds two values"
+             (assert-eq vq 3)
+             (assert-eq vr 1))
+
+         (it "errors on an arity mismatch"
+             (assert-error (define-values (x y) (values 1 2 3)))
+             (assert-error (define-values (x y z) (floor/ 7 2)))))
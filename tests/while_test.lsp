@@ -0,0 +1,57 @@
+;;; -*- mode: Scheme -*-
+
+(context "while"
+
+         ((define count 0)
+          (define total 0))
+
+         (it "runs until the condition is false"
+             (set! count 0)
+             (while (< count 5)
+               (set! count (+ count 1)))
+             (assert-eq count 5))
+
+         (it "evaluates every body form each iteration"
+             (set! count 0)
+             (set! total 0)
+             (while (< count 4)
+               (set! total (+ total count))
+               (set! count (+ count 1)))
+             (assert-eq total 6))
+
+         (it "does not run the body when the condition is initially false"
+             (set! count 10)
+             (while (< count 5)
+               (set! count 0))
+             (assert-eq count 10))
+
+         (it "returns nil"
+             (set! count 0)
+             (assert-nil (while (< count 3) (set! count (+ count 1)))))
+
+         (it "stops on an error"
+             (set! count 0)
+             (assert-error (while #t
+                             (set! count (+ count 1))
+                             (if (> count 3) (error "stop"))))
+             (assert-eq count 4)))
+
+(context "until"
+
+         ((define count 0))
+
+         (it "runs until the condition is true"
+             (set! count 0)
+             (until (eq? count 7)
+               (set! count (+ count 1)))
+             (assert-eq count 7))
+
+         (it "does not run the body when the condition is initially true"
+             (set! count 3)
+             (until #t
+               (set! count 0))
+             (assert-eq count 3))
+
+         (it "returns nil"
+             (set! count 0)
+             (assert-nil (until (eq? count 3) (set! count (+ count 1))))))
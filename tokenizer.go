@@ -159,6 +159,21 @@ func (self *Tokenizer) readBinaryNumber() (token int, lit string) {
 	return BINARYNUMBER, string(buffer)
 }
 
+func isRadixPrefix(ch rune) bool {
+	return ch == 'x' || ch == 'X' || ch == 'b' || ch == 'B'
+}
+
+// Reads a 0x/0X prefixed hex or 0b/0B prefixed binary number.
+func (self *Tokenizer) readPrefixedNumber() (token int, lit string) {
+	self.Advance()
+	radix := self.CurrentCh
+	self.Advance()
+	if radix == 'x' || radix == 'X' {
+		return self.readHexNumber()
+	}
+	return self.readBinaryNumber()
+}
+
 func (self *Tokenizer) readNumber() (token int, lit string) {
 	buffer := make([]rune, 0, 1)
 	isFloat := false
@@ -235,14 +250,18 @@ func (self *Tokenizer) readNextToken() (token int, lit string) {
 		}
 	}
 
-	if self.CurrentCh == '0' && self.NextCh == 'x' {
-		self.Advance()
-		self.Advance()
-		return self.readHexNumber()
+	if self.CurrentCh == '0' && isRadixPrefix(self.NextCh) {
+		return self.readPrefixedNumber()
 	} else if unicode.IsNumber(self.CurrentCh) {
 		return self.readNumber()
 	} else if self.CurrentCh == '-' && unicode.IsNumber(self.NextCh) {
-		return self.readNumber()
+		self.Advance()
+		if self.CurrentCh == '0' && isRadixPrefix(self.NextCh) {
+			token, lit = self.readPrefixedNumber()
+		} else {
+			token, lit = self.readNumber()
+		}
+		return token, "-" + lit
 	} else if self.CurrentCh == '"' {
 		return self.readString()
 	} else if self.CurrentCh == '\'' {
@@ -135,6 +135,20 @@ func (s *TokenizerSuite) TestUppercaseHexInteger(c *C) {
 	c.Assert(lit, Equals, "1F")
 }
 
+func (s *TokenizerSuite) TestPrefixedBinaryInteger(c *C) {
+	t := NewTokenizerFromString("0b1010 a")
+	tok, lit := t.NextToken()
+	c.Assert(tok, Equals, BINARYNUMBER)
+	c.Assert(lit, Equals, "1010")
+}
+
+func (s *TokenizerSuite) TestNegativeHexInteger(c *C) {
+	t := NewTokenizerFromString("-0X1f a")
+	tok, lit := t.NextToken()
+	c.Assert(tok, Equals, HEXNUMBER)
+	c.Assert(lit, Equals, "-1f")
+}
+
 func (s *TokenizerSuite) TestFloat(c *C) {
 	t := NewTokenizerFromString("12.345 a")
 	tok, lit := t.NextToken()